@@ -0,0 +1,443 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ContentChef/contentchef-go/contentchef"
+)
+
+func runGen(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("gen", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var f channelFlags
+	f.register(fs)
+	var (
+		definitions stringsFlag
+		samples     int
+		snapshotDir string
+		pkg         string
+		out         string
+	)
+	fs.Var(&definitions, "definition", "the definitions to generate the types of, can be repeated")
+	fs.IntVar(&samples, "samples", 20, "the number of sample contents retrieved for every definition")
+	fs.StringVar(&snapshotDir, "snapshot", "", "read the sample contents from the snapshot in this directory instead of the channel")
+	fs.StringVar(&pkg, "package", "content", "the package of the generated file")
+	fs.StringVar(&out, "out", "", "the file the generated code is written to, defaults to the standard output")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	if len(definitions) == 0 {
+		return usageError{errors.New("-definition must be set")}
+	}
+	definitions = stringsFlag(uniqueStrings(definitions))
+	if samples <= 0 {
+		return usageError{errors.New("-samples must be positive")}
+	}
+
+	var ch contentchef.Channel
+	if snapshotDir != "" {
+		snapshot, err := contentchef.LoadSnapshot(snapshotDir)
+		if err != nil {
+			return err
+		}
+		ch = contentchef.NewSnapshotChannel(snapshot)
+	} else {
+		ch, err = f.newChannel()
+		if err != nil {
+			return err
+		}
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), f.timeout)
+	defer cancel()
+
+	shapes := make([]*shape, len(definitions))
+	for i, definition := range definitions {
+		page, err := ch.Search(ctx, &contentchef.SearchOptions{ContentDefinition: []string{definition}, Take: samples})
+		if err != nil {
+			return err
+		}
+		if len(page.Items) == 0 {
+			return fmt.Errorf("no contents of definition %q found", definition)
+		}
+		shapes[i], err = inferShape(page.Items)
+		if err != nil {
+			return err
+		}
+	}
+
+	src, err := generate(pkg, definitions, shapes)
+	if err != nil {
+		return err
+	}
+	if out == "" {
+		_, err = stdout.Write(src)
+		return err
+	}
+	return ioutil.WriteFile(out, src, 0644)
+}
+
+// uniqueStrings returns values without duplicates, keeping their order.
+func uniqueStrings(values []string) []string {
+	seen := make(map[string]bool, len(values))
+	var unique []string
+	for _, v := range values {
+		if !seen[v] {
+			seen[v] = true
+			unique = append(unique, v)
+		}
+	}
+	return unique
+}
+
+// shapeKind is the kind of the JSON values seen at a place of the payloads.
+type shapeKind int
+
+const (
+	// only null values, or no values at all, were seen
+	kindNull shapeKind = iota
+	kindBool
+	kindInt
+	kindFloat
+	kindString
+	kindTime
+	kindObject
+	kindArray
+	// values of different kinds were seen
+	kindAny
+)
+
+// shape is the shape inferred from the JSON values seen at a place of the payloads.
+type shape struct {
+	kind shapeKind
+	// the number of non null values seen
+	count int
+	// the fields of objects, and the items of arrays
+	fields map[string]*shape
+	elem   *shape
+}
+
+// inferShape returns the shape of the payloads of contents.
+func inferShape(contents []contentchef.Response) (*shape, error) {
+	s := &shape{}
+	for _, content := range contents {
+		// payloads are decoded again, so that integers can be told from floats
+		data, err := json.Marshal(content.Payload)
+		if err != nil {
+			return nil, err
+		}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		var payload interface{}
+		err = dec.Decode(&payload)
+		if err != nil {
+			return nil, err
+		}
+		s.add(payload)
+	}
+	return s, nil
+}
+
+func (s *shape) add(v interface{}) {
+	if v == nil {
+		return
+	}
+	s.count++
+	s.kind = mergeKinds(s.kind, kindOf(v))
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if s.fields == nil {
+			s.fields = make(map[string]*shape)
+		}
+		for name, field := range v {
+			f := s.fields[name]
+			if f == nil {
+				f = &shape{}
+				s.fields[name] = f
+			}
+			f.add(field)
+		}
+	case []interface{}:
+		if s.elem == nil {
+			s.elem = &shape{}
+		}
+		for _, item := range v {
+			s.elem.add(item)
+		}
+	}
+}
+
+func kindOf(v interface{}) shapeKind {
+	switch v := v.(type) {
+	case bool:
+		return kindBool
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return kindInt
+		}
+		return kindFloat
+	case string:
+		if _, err := time.Parse(time.RFC3339, v); err == nil {
+			return kindTime
+		}
+		return kindString
+	case map[string]interface{}:
+		return kindObject
+	case []interface{}:
+		return kindArray
+	}
+	return kindAny
+}
+
+func mergeKinds(a, b shapeKind) shapeKind {
+	switch {
+	case a == kindNull || a == b:
+		return b
+	case a == kindInt && b == kindFloat, a == kindFloat && b == kindInt:
+		return kindFloat
+	case a == kindTime && b == kindString, a == kindString && b == kindTime:
+		return kindString
+	}
+	return kindAny
+}
+
+// generator writes the Go types of the inferred shapes.
+type generator struct {
+	buf bytes.Buffer
+	// the names of the top level declarations
+	names    map[string]bool
+	usesTime bool
+	// the structs waiting to be written
+	structs []pendingStruct
+}
+
+type pendingStruct struct {
+	name  string
+	doc   string
+	shape *shape
+}
+
+// generate returns the formatted source of a file declaring a struct for the payload of every
+// definition, with its nested structs, and typed Content and Search wrappers.
+func generate(pkg string, definitions []string, shapes []*shape) ([]byte, error) {
+	g := &generator{names: map[string]bool{"Registry": true}}
+	var body bytes.Buffer
+	names := make([]string, len(definitions))
+	for i, definition := range definitions {
+		// reserve the names of the wrappers, so that nested structs do not take them
+		name := g.uniqueName(exportedName(definition))
+		names[i] = name
+		contentName := g.uniqueName(name + "Content")
+		getName := g.uniqueName("Get" + name)
+		searchName := g.uniqueName("Search" + name)
+
+		g.structs = append(g.structs, pendingStruct{name: name, doc: fmt.Sprintf("%s is the payload of the %s definition.", name, definition), shape: shapes[i]})
+		g.writeStructs()
+		g.writeWrappers(definition, name, contentName, getName, searchName)
+		body.Write(g.buf.Bytes())
+		g.buf.Reset()
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by contentchef gen; DO NOT EDIT.\n\npackage %s\n\nimport (\n\t\"context\"\n\t\"fmt\"\n", pkg)
+	if g.usesTime {
+		fmt.Fprint(&buf, "\t\"time\"\n")
+	}
+	fmt.Fprint(&buf, "\n\t\"github.com/ContentChef/contentchef-go/contentchef\"\n)\n\n")
+	fmt.Fprint(&buf, "// Registry decodes the payloads of the generated definitions into their types.\nvar Registry = contentchef.NewRegistry()\n\nfunc init() {\n")
+	for i, definition := range definitions {
+		fmt.Fprintf(&buf, "\tRegistry.Register(%q, %s{})\n", definition, names[i])
+	}
+	fmt.Fprint(&buf, "}\n")
+	buf.Write(body.Bytes())
+	return format.Source(buf.Bytes())
+}
+
+// writeStructs writes the pending structs, and the structs nested in them.
+func (g *generator) writeStructs() {
+	for len(g.structs) > 0 {
+		s := g.structs[0]
+		g.structs = g.structs[1:]
+
+		fmt.Fprintf(&g.buf, "\n// %s\ntype %s struct {\n", s.doc, s.name)
+		names := make([]string, 0, len(s.shape.fields))
+		for name := range s.shape.fields {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		fieldNames := make(map[string]bool)
+		for _, name := range names {
+			field := s.shape.fields[name]
+			fieldName := uniqueIn(fieldNames, exportedName(name))
+			optional := field.count < s.shape.count
+			typ := g.goType(field, s.name+fieldName, fmt.Sprintf("the %s field of %s", name, s.name), optional)
+			tag := name
+			if optional {
+				tag += ",omitempty"
+			}
+			fmt.Fprintf(&g.buf, "\t%s %s `json:%q`\n", fieldName, typ, tag)
+		}
+		fmt.Fprint(&g.buf, "}\n")
+	}
+}
+
+// goType returns the Go type of s, queueing the structs it needs.
+// Optional objects and dates are pointers, so that they can be omitted.
+func (g *generator) goType(s *shape, name, place string, optional bool) string {
+	pointer := ""
+	if optional {
+		pointer = "*"
+	}
+	switch s.kind {
+	case kindBool:
+		return "bool"
+	case kindInt:
+		return "int"
+	case kindFloat:
+		return "float64"
+	case kindString:
+		return "string"
+	case kindTime:
+		g.usesTime = true
+		return pointer + "time.Time"
+	case kindObject:
+		name = g.uniqueName(name)
+		g.structs = append(g.structs, pendingStruct{name: name, doc: fmt.Sprintf("%s is %s.", name, place), shape: s})
+		return pointer + name
+	case kindArray:
+		if s.elem == nil || s.elem.kind == kindNull {
+			return "[]interface{}"
+		}
+		return "[]" + g.goType(s.elem, name+"Item", "an item of "+place, false)
+	}
+	return "interface{}"
+}
+
+// writeWrappers writes the typed content of a definition, and its Content and Search wrappers.
+func (g *generator) writeWrappers(definition, name, contentName, getName, searchName string) {
+	fmt.Fprintf(&g.buf, `
+// %[2]s is a content of the %[1]s definition, with its typed payload.
+type %[2]s struct {
+	contentchef.Response
+	Payload *%[3]s `+"`json:\"payload\"`"+`
+}
+
+// %[4]s retrieves a content of the %[1]s definition from ch.
+func %[4]s(ctx context.Context, ch contentchef.Channel, config *contentchef.ContentOptions) (*%[2]s, error) {
+	r, err := Registry.Content(ctx, ch, config)
+	if err != nil {
+		return nil, err
+	}
+	payload, ok := r.Payload.(*%[3]s)
+	if !ok {
+		return nil, fmt.Errorf("content %%q is of definition %%q, not %%q", r.PublicID, r.Definition, %[1]q)
+	}
+	return &%[2]s{Response: *r, Payload: payload}, nil
+}
+
+// %[5]s searches the contents of the %[1]s definition of ch, and returns them with the total
+// number of matching contents. The definition of config is overridden.
+func %[5]s(ctx context.Context, ch contentchef.Channel, config *contentchef.SearchOptions) ([]%[2]s, int, error) {
+	c := contentchef.SearchOptions{}
+	if config != nil {
+		c = *config
+	}
+	c.ContentDefinition = []string{%[1]q}
+	page, err := Registry.Search(ctx, ch, &c)
+	if err != nil {
+		return nil, 0, err
+	}
+	items := make([]%[2]s, len(page.Items))
+	for i, r := range page.Items {
+		payload, ok := r.Payload.(*%[3]s)
+		if !ok {
+			return nil, 0, fmt.Errorf("content %%q is of definition %%q, not %%q", r.PublicID, r.Definition, %[1]q)
+		}
+		items[i] = %[2]s{Response: r, Payload: payload}
+	}
+	return items, page.Total, nil
+}
+`, definition, contentName, name, getName, searchName)
+}
+
+// uniqueName returns name, followed by a number if a top level declaration already has it.
+func (g *generator) uniqueName(name string) string {
+	return uniqueIn(g.names, name)
+}
+
+// uniqueIn returns name, followed by a number if it is already in used, and adds it to used.
+func uniqueIn(used map[string]bool, name string) string {
+	unique := name
+	for i := 2; used[unique]; i++ {
+		unique = fmt.Sprintf("%s%d", name, i)
+	}
+	used[unique] = true
+	return unique
+}
+
+// initialisms are the words written upper case in Go names.
+var initialisms = map[string]bool{
+	"API": true, "CSS": true, "HTML": true, "HTTP": true, "ID": true, "JSON": true,
+	"SQL": true, "URI": true, "URL": true, "UUID": true,
+}
+
+// exportedName turns a JSON field or definition name, like publicId or blog-post,
+// into an exported Go name, like PublicID or BlogPost.
+func exportedName(s string) string {
+	var (
+		words []string
+		word  []rune
+	)
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, string(word))
+			word = nil
+		}
+	}
+	runes := []rune(s)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+			continue
+		case unicode.IsUpper(r) && i > 0 && (unicode.IsLower(runes[i-1]) ||
+			unicode.IsUpper(runes[i-1]) && i+1 < len(runes) && unicode.IsLower(runes[i+1])):
+			// a new word starts at an upper case letter following a lower case one,
+			// or at the last upper case letter of an initialism followed by a word
+			flush()
+		}
+		word = append(word, r)
+	}
+	flush()
+
+	var b strings.Builder
+	for _, w := range words {
+		if upper := strings.ToUpper(w); initialisms[upper] {
+			b.WriteString(upper)
+			continue
+		}
+		r := []rune(w)
+		b.WriteRune(unicode.ToUpper(r[0]))
+		b.WriteString(string(r[1:]))
+	}
+	name := b.String()
+	if name == "" {
+		return "Field"
+	}
+	if unicode.IsDigit([]rune(name)[0]) {
+		return "X" + name
+	}
+	return name
+}
@@ -0,0 +1,202 @@
+package main
+
+import (
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ContentChef/contentchef-go/contentchef"
+	"github.com/ContentChef/contentchef-go/contentchef/contentcheftest"
+)
+
+func newGenTestServer(t *testing.T) *contentcheftest.Server {
+	srv := contentcheftest.NewServer("mySpace")
+	err := srv.AddChannel(contentcheftest.Channel{
+		Name:         "website",
+		OnlineAPIKey: "onlineKey",
+		Live: []contentchef.Response{
+			{PublicID: "go", Definition: "blog-post", Payload: map[string]interface{}{
+				"title": "Learning Go", "views": 30, "rating": 4.5, "published": "2020-04-09T22:00:00Z",
+				"author": map[string]interface{}{"name": "Ann", "publicId": "ann"},
+				"blocks": []interface{}{map[string]interface{}{"text": "Hello"}},
+				"tags":   []interface{}{"go"},
+			}},
+			{PublicID: "rust", Definition: "blog-post", Payload: map[string]interface{}{
+				"title": "Learning Rust", "views": 10, "rating": 4, "published": "yesterday",
+				"blocks": []interface{}{}, "extra": nil,
+			}},
+			{PublicID: "home", Definition: "page", Payload: map[string]interface{}{"title": "Home", "seoUrl": "/", "x": true}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddChannel() returned error: %v", err)
+	}
+	return srv
+}
+
+// the importer is shared by the tests, so that the imported packages are type checked once
+var (
+	genFset     = token.NewFileSet()
+	genImporter = importer.ForCompiler(genFset, "source", nil)
+)
+
+// typeCheck parses and type checks the generated source.
+func typeCheck(t *testing.T, src string) *types.Package {
+	f, err := parser.ParseFile(genFset, "types.go", src, parser.ParseComments)
+	if err != nil {
+		t.Fatalf("Cannot parse the generated code: %v\n%s", err, src)
+	}
+	conf := types.Config{Importer: genImporter}
+	pkg, err := conf.Check("content", genFset, []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatalf("Cannot type check the generated code: %v\n%s", err, src)
+	}
+	return pkg
+}
+
+func TestRun_gen(t *testing.T) {
+	srv := newGenTestServer(t)
+	defer srv.Close()
+
+	stdout, stderr, code := runTest(t, srv, "gen", "-api-key", "onlineKey", "-definition", "blog-post,page", "-definition", "page")
+	if code != 0 {
+		t.Fatalf("run() = %d, stderr: %s", code, stderr)
+	}
+	pkg := typeCheck(t, stdout)
+
+	wantFields := map[string][]string{
+		"BlogPost": {
+			"Author *BlogPostAuthor `json:\"author,omitempty\"`",
+			"Blocks []BlogPostBlocksItem `json:\"blocks\"`",
+			"Extra interface{} `json:\"extra,omitempty\"`",
+			"Published string `json:\"published\"`",
+			"Rating float64 `json:\"rating\"`",
+			"Tags []string `json:\"tags,omitempty\"`",
+			"Title string `json:\"title\"`",
+			"Views int `json:\"views\"`",
+		},
+		"BlogPostAuthor": {"Name string `json:\"name\"`", "PublicID string `json:\"publicId\"`"},
+		"Page":           {"SeoURL string `json:\"seoUrl\"`", "Title string `json:\"title\"`", "X bool `json:\"x\"`"},
+	}
+	for name, fields := range wantFields {
+		obj := pkg.Scope().Lookup(name)
+		if obj == nil {
+			t.Errorf("Expected a %s type to be generated", name)
+			continue
+		}
+		s := obj.Type().Underlying().(*types.Struct)
+		var got []string
+		for i := 0; i < s.NumFields(); i++ {
+			got = append(got, s.Field(i).Name()+" "+types.TypeString(s.Field(i).Type(), types.RelativeTo(pkg))+" `"+s.Tag(i)+"`")
+		}
+		if strings.Join(got, "\n") != strings.Join(fields, "\n") {
+			t.Errorf("Fields of %s:\n%s\nwant:\n%s", name, strings.Join(got, "\n"), strings.Join(fields, "\n"))
+		}
+	}
+	for _, name := range []string{"Registry", "BlogPostContent", "GetBlogPost", "SearchBlogPost", "PageContent", "GetPage", "SearchPage", "BlogPostBlocksItem"} {
+		if pkg.Scope().Lookup(name) == nil {
+			t.Errorf("Expected %s to be generated", name)
+		}
+	}
+	if !strings.HasPrefix(stdout, "// Code generated by contentchef gen; DO NOT EDIT.") {
+		t.Errorf("Expected the generated code header, got %q", stdout[:50])
+	}
+}
+
+func TestRun_genSnapshot(t *testing.T) {
+	dir, err := ioutil.TempDir("", "contentchef")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	snapshot := &contentchef.Snapshot{Contents: []contentchef.Response{
+		{PublicID: "go", Definition: "article", Payload: map[string]interface{}{"title": "Learning Go", "onlineAt": "2020-04-09T22:00:00Z"}},
+	}}
+	err = snapshot.Save(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := filepath.Join(dir, "types.go")
+
+	var stdout, stderr strings.Builder
+	code := run([]string{"gen", "-snapshot", dir, "-definition", "article", "-package", "types", "-out", out}, &stdout, &stderr)
+	if code != 0 {
+		t.Fatalf("run() = %d, stderr: %s", code, stderr.String())
+	}
+	src, err := ioutil.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	typeCheck(t, string(src))
+	if !strings.Contains(string(src), "package types") || !strings.Contains(string(src), "OnlineAt time.Time `json:\"onlineAt\"`") {
+		t.Errorf("Unexpected generated code:\n%s", src)
+	}
+}
+
+func TestRun_genErrors(t *testing.T) {
+	srv := newGenTestServer(t)
+	defer srv.Close()
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+	}{
+		{name: "missing definition", args: []string{"gen", "-api-key", "onlineKey"}, wantCode: 2},
+		{name: "invalid samples", args: []string{"gen", "-api-key", "onlineKey", "-definition", "page", "-samples", "0"}, wantCode: 2},
+		{name: "no contents", args: []string{"gen", "-api-key", "onlineKey", "-definition", "video"}, wantCode: 1},
+		{name: "missing snapshot", args: []string{"gen", "-snapshot", "/nonexistent", "-definition", "page"}, wantCode: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, stderr, code := runTest(t, srv, tt.args...)
+			if code != tt.wantCode {
+				t.Errorf("run() = %d, want %d, stderr: %s", code, tt.wantCode, stderr)
+			}
+		})
+	}
+}
+
+func Test_exportedName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{name: "title", want: "Title"},
+		{name: "publicId", want: "PublicID"},
+		{name: "blog-post", want: "BlogPost"},
+		{name: "seo_url", want: "SeoURL"},
+		{name: "HTMLBody", want: "HTMLBody"},
+		{name: "imageURLs", want: "ImageURLs"},
+		{name: "2col", want: "X2col"},
+		{name: "-", want: "Field"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exportedName(tt.name); got != tt.want {
+				t.Errorf("exportedName() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_generateNameCollisions(t *testing.T) {
+	shapes := []*shape{{kind: kindObject}, {kind: kindObject}, {kind: kindObject}}
+	src, err := generate("content", []string{"registry", "page", "page-content"}, shapes)
+	if err != nil {
+		t.Fatalf("generate() returned error: %v", err)
+	}
+	pkg := typeCheck(t, string(src))
+	for _, name := range []string{"Registry2", "Page", "PageContent", "PageContent2", "PageContent2Content"} {
+		if pkg.Scope().Lookup(name) == nil {
+			t.Errorf("Expected %s to be generated", name)
+		}
+	}
+}
@@ -0,0 +1,376 @@
+// Command contentchef queries the contents of a ContentChef channel.
+//
+// Usage:
+//
+//	contentchef <command> [flags]
+//
+// The commands are:
+//
+//	content    retrieve a single content by its publicId
+//	search     search for contents
+//	snapshot   save every content of a channel to a directory
+//	gen        generate Go types out of sample contents
+//
+// The base URL, the space ID and the API key default to the CONTENTCHEF_BASE_URL,
+// CONTENTCHEF_SPACE_ID and CONTENTCHEF_API_KEY environment variables.
+//
+// Examples:
+//
+//	contentchef content -space mySpace -channel website -api-key xxx -public-id home
+//	contentchef search -channel website -definition article -filter title:CONTAINS_IC:go -sort -onlineDate -output table
+//	contentchef search -channel website -preview -state staging -target-date 2020-04-09T22:00:00Z -all -output ndjson
+//	contentchef snapshot -channel website -definition article -out ./snapshot
+//	contentchef gen -channel website -definition article,page -package content -out content/types.go
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ContentChef/contentchef-go/contentchef"
+)
+
+const defaultBaseURL = "https://api.contentchef.io/"
+
+const usage = `Usage: contentchef <command> [flags]
+
+Commands:
+  content    retrieve a single content by its publicId
+  search     search for contents
+  snapshot   save every content of a channel to a directory
+  gen        generate Go types out of sample contents
+
+Run 'contentchef <command> -h' to list the flags of a command.
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return 2
+	}
+
+	var err error
+	switch args[0] {
+	case "content":
+		err = runContent(args[1:], stdout, stderr)
+	case "search":
+		err = runSearch(args[1:], stdout, stderr)
+	case "snapshot":
+		err = runSnapshot(args[1:], stdout, stderr)
+	case "gen":
+		err = runGen(args[1:], stdout, stderr)
+	case "-h", "-help", "--help", "help":
+		fmt.Fprint(stdout, usage)
+		return 0
+	default:
+		fmt.Fprintf(stderr, "contentchef: unknown command %q\n\n%s", args[0], usage)
+		return 2
+	}
+
+	if err == flag.ErrHelp {
+		return 0
+	}
+	if _, ok := err.(usageError); ok {
+		fmt.Fprintf(stderr, "contentchef: %v\n", err)
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "contentchef: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// usageError is returned when the command line is not valid.
+type usageError struct {
+	error
+}
+
+// repeatedFlag is a flag which can be repeated.
+type repeatedFlag []string
+
+func (s *repeatedFlag) String() string {
+	return strings.Join(*s, " ")
+}
+
+func (s *repeatedFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// stringsFlag is a flag which can be repeated, or set to a comma separated list of values.
+type stringsFlag []string
+
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringsFlag) Set(value string) error {
+	for _, v := range strings.Split(value, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			*s = append(*s, v)
+		}
+	}
+	return nil
+}
+
+// channelFlags are the flags shared by every command.
+type channelFlags struct {
+	baseURL    string
+	spaceID    string
+	channel    string
+	apiKey     string
+	preview    bool
+	state      string
+	targetDate string
+	output     string
+	timeout    time.Duration
+}
+
+func (f *channelFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.baseURL, "base-url", envOr("CONTENTCHEF_BASE_URL", defaultBaseURL), "the base URL of the ContentChef API")
+	fs.StringVar(&f.spaceID, "space", os.Getenv("CONTENTCHEF_SPACE_ID"), "the ID of the space")
+	fs.StringVar(&f.channel, "channel", "", "the name of the publishing channel")
+	fs.StringVar(&f.apiKey, "api-key", os.Getenv("CONTENTCHEF_API_KEY"), "the API key of the channel")
+	fs.BoolVar(&f.preview, "preview", false, "query the preview channel instead of the online one")
+	fs.StringVar(&f.state, "state", "live", "the publishing state queried by the preview channel, live or staging")
+	fs.StringVar(&f.targetDate, "target-date", "", "the date, in RFC 3339 format, at which the preview channel is queried")
+	fs.StringVar(&f.output, "output", "json", "the output format: json, table or ndjson")
+	fs.DurationVar(&f.timeout, "timeout", 30*time.Second, "the maximum duration of the command")
+}
+
+func (f *channelFlags) newChannel() (contentchef.Channel, error) {
+	if f.output != "json" && f.output != "table" && f.output != "ndjson" {
+		return nil, usageError{fmt.Errorf("unknown output format %q", f.output)}
+	}
+	opts := &contentchef.ClientOptions{
+		BaseURL: f.baseURL,
+		SpaceID: f.spaceID,
+	}
+	if f.targetDate != "" {
+		if !f.preview {
+			return nil, usageError{errors.New("-target-date can only be used with -preview")}
+		}
+		date, err := time.Parse(time.RFC3339, f.targetDate)
+		if err != nil {
+			return nil, usageError{fmt.Errorf("invalid -target-date: %v", err)}
+		}
+		opts.TargetDate = date
+	}
+	client, err := contentchef.NewClient(opts)
+	if err != nil {
+		return nil, usageError{err}
+	}
+	if f.preview {
+		ch, err := client.GetPreviewChannel(f.channel, f.apiKey, f.state)
+		if err != nil {
+			return nil, usageError{err}
+		}
+		return ch, nil
+	}
+	ch, err := client.GetOnlineChannel(f.channel, f.apiKey)
+	if err != nil {
+		return nil, usageError{err}
+	}
+	return ch, nil
+}
+
+func runContent(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("content", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var f channelFlags
+	f.register(fs)
+	var config contentchef.ContentOptions
+	fs.StringVar(&config.PublicID, "public-id", "", "the publicId of the content")
+	fs.BoolVar(&config.LegacyMetadata, "legacy-metadata", false, "retrieve the legacy metadata")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	if config.PublicID == "" {
+		return usageError{errors.New("-public-id must be set")}
+	}
+
+	ch, err := f.newChannel()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), f.timeout)
+	defer cancel()
+
+	content, err := ch.Content(ctx, &config)
+	if err != nil {
+		return err
+	}
+	return writeContents(stdout, f.output, content, []contentchef.Response{*content})
+}
+
+func runSearch(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("search", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var f channelFlags
+	f.register(fs)
+	var (
+		config      contentchef.SearchOptions
+		publicIDs   stringsFlag
+		definitions stringsFlag
+		repos       stringsFlag
+		tags        stringsFlag
+		filters     repeatedFlag
+		sorting     stringsFlag
+		condition   string
+		propFilters string
+		all         bool
+	)
+	fs.IntVar(&config.Skip, "skip", 0, "the number of contents to skip")
+	fs.IntVar(&config.Take, "take", 10, "the number of contents to retrieve, or the page size with -all")
+	fs.Var(&publicIDs, "public-id", "the publicIds of the contents, can be repeated")
+	fs.Var(&definitions, "definition", "the definitions of the contents, can be repeated")
+	fs.Var(&repos, "repository", "the repositories of the contents, can be repeated")
+	fs.Var(&tags, "tag", "the tags of the contents, can be repeated")
+	fs.BoolVar(&config.LegacyMetadata, "legacy-metadata", false, "retrieve the legacy metadata")
+	fs.Var(&filters, "filter", "a property filter in the form field:OPERATOR:value, can be repeated,\nIN operators take a comma separated list of values")
+	fs.StringVar(&condition, "condition", "AND", "the condition joining the property filters, AND or OR")
+	fs.StringVar(&propFilters, "prop-filters", "", "the property filters as JSON, overrides -filter and -condition")
+	fs.Var(&sorting, "sort", "a field to sort by, prefixed by + (ascending) or - (descending), can be repeated")
+	fs.BoolVar(&all, "all", false, "retrieve every matching content walking through all the pages")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+
+	config.PublicID = publicIDs
+	config.ContentDefinition = definitions
+	config.Repositories = repos
+	config.Tags = tags
+	config.Sorting = parseSorting(sorting)
+	config.PropFilters, err = parsePropFilters(filters, condition, propFilters)
+	if err != nil {
+		return usageError{err}
+	}
+
+	ch, err := f.newChannel()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), f.timeout)
+	defer cancel()
+
+	if all {
+		items, err := contentchef.SearchAll(ctx, ch, &config)
+		if err != nil {
+			return err
+		}
+		return writeContents(stdout, f.output, items, items)
+	}
+	page, err := ch.Search(ctx, &config)
+	if err != nil {
+		return err
+	}
+	return writeContents(stdout, f.output, page, page.Items)
+}
+
+func runSnapshot(args []string, stdout, stderr io.Writer) error {
+	fs := flag.NewFlagSet("snapshot", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	var f channelFlags
+	f.register(fs)
+	var (
+		config      contentchef.SearchOptions
+		definitions stringsFlag
+		repos       stringsFlag
+		out         string
+	)
+	fs.IntVar(&config.Take, "take", contentchef.DefaultPageSize, "the page size")
+	fs.Var(&definitions, "definition", "the definitions of the contents, can be repeated")
+	fs.Var(&repos, "repository", "the repositories of the contents, can be repeated")
+	fs.StringVar(&out, "out", "", "the directory the snapshot is saved to")
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	if out == "" {
+		return usageError{errors.New("-out must be set")}
+	}
+	config.ContentDefinition = definitions
+	config.Repositories = repos
+
+	ch, err := f.newChannel()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), f.timeout)
+	defer cancel()
+
+	snapshot, err := contentchef.TakeSnapshot(ctx, ch, &config)
+	if err != nil {
+		return err
+	}
+	err = snapshot.Save(out)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(stdout, "saved %d contents to %s\n", snapshot.Manifest.Total, out)
+	return nil
+}
+
+// parseSorting parses fields like +publicId or -onlineDate, fields without prefix are sorted ascending.
+func parseSorting(fields []string) contentchef.Sorting {
+	var s contentchef.Sorting
+	for _, field := range fields {
+		s = append(s, contentchef.SortingField{
+			FieldName: strings.TrimLeft(field, "+-"),
+			Ascending: !strings.HasPrefix(field, "-"),
+		})
+	}
+	return s
+}
+
+// parsePropFilters builds the property filters out of either the -prop-filters JSON
+// or the -filter flags, in the form field:OPERATOR:value.
+func parsePropFilters(filters []string, condition, raw string) (contentchef.PropFilters, error) {
+	var p contentchef.PropFilters
+	if raw != "" {
+		err := json.Unmarshal([]byte(raw), &p)
+		if err != nil {
+			return p, fmt.Errorf("invalid -prop-filters: %v", err)
+		}
+		return p, p.Validate()
+	}
+	if len(filters) == 0 {
+		return p, nil
+	}
+
+	p.Condition = strings.ToUpper(condition)
+	for _, filter := range filters {
+		parts := strings.SplitN(filter, ":", 3)
+		if len(parts) != 3 {
+			return p, fmt.Errorf("invalid -filter %q, expected field:OPERATOR:value", filter)
+		}
+		item := contentchef.PropFilterItem{
+			Field:    parts[0],
+			Operator: strings.ToUpper(parts[1]),
+			Value:    parts[2],
+		}
+		if item.Operator == contentchef.OperatorIn || item.Operator == contentchef.OperatorInIC {
+			var values []interface{}
+			for _, v := range strings.Split(parts[2], ",") {
+				values = append(values, strings.TrimSpace(v))
+			}
+			item.Value = values
+		}
+		p.Items = append(p.Items, item)
+	}
+	return p, p.Validate()
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ContentChef/contentchef-go/contentchef"
+	"github.com/ContentChef/contentchef-go/contentchef/contentcheftest"
+)
+
+func newTestServer(t *testing.T) *contentcheftest.Server {
+	srv := contentcheftest.NewServer("mySpace")
+	err := srv.AddChannel(contentcheftest.Channel{
+		Name:          "website",
+		OnlineAPIKey:  "onlineKey",
+		PreviewAPIKey: "previewKey",
+		Live: []contentchef.Response{
+			{PublicID: "home", Definition: "page", Payload: map[string]interface{}{"title": "Home"}},
+			{PublicID: "go", Definition: "article", Payload: map[string]interface{}{"title": "Learning Go", "views": 30}},
+			{PublicID: "rust", Definition: "article", Payload: map[string]interface{}{"title": "Learning Rust", "views": 10}},
+		},
+		Staging: []contentchef.Response{
+			{PublicID: "draft", Definition: "article", Payload: map[string]interface{}{"title": "Draft"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddChannel() returned error: %v", err)
+	}
+	return srv
+}
+
+func runTest(t *testing.T, srv *contentcheftest.Server, args ...string) (string, string, int) {
+	var stdout, stderr bytes.Buffer
+	args = append(args, "-base-url", srv.URL+"/", "-space", "mySpace", "-channel", "website")
+	code := run(args, &stdout, &stderr)
+	return stdout.String(), stderr.String(), code
+}
+
+func TestRun_content(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	stdout, stderr, code := runTest(t, srv, "content", "-api-key", "onlineKey", "-public-id", "home")
+	if code != 0 {
+		t.Fatalf("run() = %d, stderr: %s", code, stderr)
+	}
+	var got contentchef.Response
+	if err := json.Unmarshal([]byte(stdout), &got); err != nil {
+		t.Fatalf("Cannot decode output %q: %v", stdout, err)
+	}
+	if got.PublicID != "home" {
+		t.Errorf("PublicID = %v, want home", got.PublicID)
+	}
+}
+
+func TestRun_contentPreview(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	stdout, stderr, code := runTest(t, srv, "content", "-api-key", "previewKey", "-public-id", "draft",
+		"-preview", "-state", "staging", "-target-date", "2020-04-09T22:00:00Z", "-output", "ndjson")
+	if code != 0 {
+		t.Fatalf("run() = %d, stderr: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, `"publicId":"draft"`) || strings.Count(stdout, "\n") != 1 {
+		t.Errorf("Unexpected output %q", stdout)
+	}
+}
+
+func TestRun_search(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	stdout, stderr, code := runTest(t, srv, "search", "-api-key", "onlineKey", "-definition", "article",
+		"-filter", "title:CONTAINS_IC:learning", "-sort", "-views", "-output", "ndjson")
+	if code != 0 {
+		t.Fatalf("run() = %d, stderr: %s", code, stderr)
+	}
+	var ids []string
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		var item contentchef.Response
+		if err := json.Unmarshal([]byte(line), &item); err != nil {
+			t.Fatalf("Cannot decode line %q: %v", line, err)
+		}
+		ids = append(ids, item.PublicID)
+	}
+	if want := []string{"go", "rust"}; !reflect.DeepEqual(ids, want) {
+		t.Errorf("Search output = %v, want %v", ids, want)
+	}
+}
+
+func TestRun_searchAllTable(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	stdout, stderr, code := runTest(t, srv, "search", "-api-key", "onlineKey", "-all", "-take", "1", "-sort", "+publicId", "-output", "table")
+	if code != 0 {
+		t.Fatalf("run() = %d, stderr: %s", code, stderr)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "PUBLIC ID") || !strings.HasPrefix(lines[1], "go ") {
+		t.Errorf("Unexpected table %q", stdout)
+	}
+}
+
+func TestRun_snapshot(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+	dir, err := ioutil.TempDir("", "contentchef")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	stdout, stderr, code := runTest(t, srv, "snapshot", "-api-key", "onlineKey", "-definition", "article", "-take", "1", "-out", dir)
+	if code != 0 {
+		t.Fatalf("run() = %d, stderr: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "saved 2 contents") {
+		t.Errorf("Unexpected output %q", stdout)
+	}
+	snapshot, err := contentchef.LoadSnapshot(dir)
+	if err != nil {
+		t.Fatalf("LoadSnapshot() returned error: %v", err)
+	}
+	if snapshot.Manifest.Channel != "website" || len(snapshot.Contents) != 2 {
+		t.Errorf("Unexpected snapshot %+v", snapshot.Manifest)
+	}
+}
+
+func TestRun_errors(t *testing.T) {
+	srv := newTestServer(t)
+	defer srv.Close()
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+	}{
+		{name: "unknown command", args: []string{"publish"}, wantCode: 2},
+		{name: "missing publicId", args: []string{"content", "-api-key", "onlineKey"}, wantCode: 2},
+		{name: "unknown output", args: []string{"content", "-api-key", "onlineKey", "-public-id", "home", "-output", "xml"}, wantCode: 2},
+		{name: "target date without preview", args: []string{"content", "-api-key", "onlineKey", "-public-id", "home", "-target-date", "2020-04-09T22:00:00Z"}, wantCode: 2},
+		{name: "snapshot without out", args: []string{"snapshot", "-api-key", "onlineKey"}, wantCode: 2},
+		{name: "invalid filter", args: []string{"search", "-api-key", "onlineKey", "-filter", "title"}, wantCode: 2},
+		{name: "wrong api key", args: []string{"content", "-api-key", "previewKey", "-public-id", "home"}, wantCode: 1},
+		{name: "content not found", args: []string{"content", "-api-key", "onlineKey", "-public-id", "missing"}, wantCode: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, stderr, code := runTest(t, srv, tt.args...)
+			if code != tt.wantCode {
+				t.Errorf("run() = %d, want %d, stderr: %s", code, tt.wantCode, stderr)
+			}
+		})
+	}
+}
+
+func Test_parsePropFilters(t *testing.T) {
+	got, err := parsePropFilters([]string{"title:contains_ic:hello, world", "views:IN:10, 20"}, "or", "")
+	if err != nil {
+		t.Fatalf("parsePropFilters() returned error: %v", err)
+	}
+	want := contentchef.PropFilters{
+		Condition: "OR",
+		Items: []contentchef.PropFilterItem{
+			{Field: "title", Operator: "CONTAINS_IC", Value: "hello, world"},
+			{Field: "views", Operator: "IN", Value: []interface{}{"10", "20"}},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parsePropFilters() = %#v, want %#v", got, want)
+	}
+
+	got, err = parsePropFilters(nil, "AND", `{"condition": "AND", "items": [{"field": "title", "operator": "EQUALS", "value": "Home"}]}`)
+	if err != nil || len(got.Items) != 1 || got.Items[0].Value != "Home" {
+		t.Errorf("parsePropFilters() with JSON = %#v, %v", got, err)
+	}
+}
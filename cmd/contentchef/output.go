@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/ContentChef/contentchef-go/contentchef"
+)
+
+// writeContents writes the result of a command in the given format.
+// The json format writes v as it is, while table and ndjson write one content per line.
+func writeContents(w io.Writer, format string, v interface{}, items []contentchef.Response) error {
+	switch format {
+	case "table":
+		return writeTable(w, items)
+	case "ndjson":
+		enc := json.NewEncoder(w)
+		for _, item := range items {
+			err := enc.Encode(item)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+}
+
+func writeTable(w io.Writer, items []contentchef.Response) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "PUBLIC ID\tDEFINITION\tREPOSITORY\tONLINE DATE\tOFFLINE DATE\tTAGS")
+	for _, item := range items {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			item.PublicID, item.Definition, item.Repository,
+			formatDate(item.OnlineDate), formatDate(item.OfflineDate),
+			strings.Join(item.Metadata.Tags, ","))
+	}
+	return tw.Flush()
+}
+
+func formatDate(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format(time.RFC3339)
+}
+
+func envOr(name, fallback string) string {
+	if v := os.Getenv(name); v != "" {
+		return v
+	}
+	return fallback
+}
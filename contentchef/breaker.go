@@ -0,0 +1,255 @@
+package contentchef
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned, without calling the API, while the circuit breaker is open.
+var ErrCircuitOpen = errors.New("contentchef: circuit breaker is open")
+
+// CircuitState is the state of a circuit breaker.
+type CircuitState int
+
+// The circuit breaker states.
+const (
+	// Requests are sent and their outcome recorded
+	CircuitClosed CircuitState = iota
+	// Requests fail fast with ErrCircuitOpen
+	CircuitOpen
+	// A limited number of probe requests are sent to check whether the API recovered
+	CircuitHalfOpen
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
+const (
+	defaultBreakerFailureRatio = 0.5
+	defaultBreakerMinRequests  = 10
+	defaultBreakerWindow       = time.Minute
+	defaultBreakerOpenTimeout  = 30 * time.Second
+	defaultBreakerProbes       = 1
+)
+
+// CircuitBreakerOptions configures the circuit breaker of a Client.
+//
+// The breaker counts the requests sent in a window of time; when at least MinRequests were sent
+// and the ratio of failures reaches FailureRatio it opens. Server errors, network errors and
+// requests slower than SlowThreshold are counted as failures. After OpenTimeout it becomes
+// half-open and lets HalfOpenProbes requests through, closing again if they all succeed or
+// opening again on the first failure. Zero values are replaced by sensible defaults.
+type CircuitBreakerOptions struct {
+	// The ratio of failed requests, between 0 and 1, which opens the breaker. Defaults to 0.5
+	FailureRatio float64
+	// The minimum number of requests in the window before the breaker can open. Defaults to 10
+	MinRequests int
+	// The duration of the window in which requests are counted. Defaults to 1m
+	Window time.Duration
+	// Requests taking longer than SlowThreshold are counted as failures, if 0 latency is not checked
+	SlowThreshold time.Duration
+	// How long the breaker stays open before letting probe requests through. Defaults to 30s
+	OpenTimeout time.Duration
+	// The number of probe requests sent while half-open. Defaults to 1
+	HalfOpenProbes int
+	// OnStateChange is called, if set, every time the breaker changes state.
+	// It is called by the request causing the change, once the breaker is unlocked
+	OnStateChange func(from, to CircuitState)
+}
+
+// circuitBreaker implements the CircuitBreakerOptions' state machine.
+type circuitBreaker struct {
+	opts CircuitBreakerOptions
+
+	mu          sync.Mutex
+	state       CircuitState
+	windowStart time.Time
+	requests    int
+	failures    int
+	openedAt    time.Time
+	probes      int
+	successes   int
+	changes     []stateChange
+
+	now func() time.Time
+}
+
+type stateChange struct {
+	from, to CircuitState
+}
+
+func newCircuitBreaker(o *CircuitBreakerOptions) *circuitBreaker {
+	if o == nil {
+		return nil
+	}
+	opts := *o
+	if opts.FailureRatio <= 0 {
+		opts.FailureRatio = defaultBreakerFailureRatio
+	}
+	if opts.MinRequests <= 0 {
+		opts.MinRequests = defaultBreakerMinRequests
+	}
+	if opts.Window <= 0 {
+		opts.Window = defaultBreakerWindow
+	}
+	if opts.OpenTimeout <= 0 {
+		opts.OpenTimeout = defaultBreakerOpenTimeout
+	}
+	if opts.HalfOpenProbes <= 0 {
+		opts.HalfOpenProbes = defaultBreakerProbes
+	}
+	return &circuitBreaker{opts: opts, now: time.Now}
+}
+
+// State returns the current state of the breaker.
+func (b *circuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	b.advance(b.now())
+	state := b.state
+	changes := b.takeChanges()
+	b.mu.Unlock()
+
+	b.notify(changes)
+	return state
+}
+
+// allow reports whether a request can be sent, returning ErrCircuitOpen if it cannot.
+// Every allowed request must be followed by a call to either record or ignore.
+func (b *circuitBreaker) allow() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	b.advance(b.now())
+	var err error
+	switch b.state {
+	case CircuitOpen:
+		err = ErrCircuitOpen
+	case CircuitHalfOpen:
+		if b.probes >= b.opts.HalfOpenProbes {
+			err = ErrCircuitOpen
+		} else {
+			b.probes++
+		}
+	}
+	changes := b.takeChanges()
+	b.mu.Unlock()
+
+	b.notify(changes)
+	return err
+}
+
+// record records the outcome of a request allowed by the breaker.
+func (b *circuitBreaker) record(failed bool, latency time.Duration) {
+	if b == nil {
+		return
+	}
+	if b.opts.SlowThreshold > 0 && latency > b.opts.SlowThreshold {
+		failed = true
+	}
+
+	b.mu.Lock()
+	now := b.now()
+	b.advance(now)
+	switch b.state {
+	case CircuitHalfOpen:
+		if failed {
+			b.setState(CircuitOpen, now)
+			break
+		}
+		b.successes++
+		if b.successes >= b.opts.HalfOpenProbes {
+			b.setState(CircuitClosed, now)
+		}
+	case CircuitClosed:
+		b.requests++
+		if failed {
+			b.failures++
+		}
+		if b.requests >= b.opts.MinRequests && float64(b.failures)/float64(b.requests) >= b.opts.FailureRatio {
+			b.setState(CircuitOpen, now)
+		}
+	}
+	changes := b.takeChanges()
+	b.mu.Unlock()
+
+	b.notify(changes)
+}
+
+// ignore releases a request allowed by the breaker whose outcome says nothing about the API,
+// like a request canceled by its caller.
+func (b *circuitBreaker) ignore() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == CircuitHalfOpen && b.probes > 0 {
+		b.probes--
+	}
+}
+
+// advance moves the breaker from open to half-open once OpenTimeout elapsed,
+// and starts a new counting window once the current one elapsed.
+func (b *circuitBreaker) advance(now time.Time) {
+	switch b.state {
+	case CircuitOpen:
+		if now.Sub(b.openedAt) >= b.opts.OpenTimeout {
+			b.setState(CircuitHalfOpen, now)
+		}
+	case CircuitClosed:
+		if now.Sub(b.windowStart) >= b.opts.Window {
+			b.resetWindow(now)
+		}
+	}
+}
+
+func (b *circuitBreaker) resetWindow(now time.Time) {
+	b.windowStart = now
+	b.requests = 0
+	b.failures = 0
+}
+
+func (b *circuitBreaker) setState(state CircuitState, now time.Time) {
+	if b.state == state {
+		return
+	}
+	b.changes = append(b.changes, stateChange{from: b.state, to: state})
+	b.state = state
+	switch state {
+	case CircuitOpen:
+		b.openedAt = now
+	case CircuitHalfOpen:
+		b.probes = 0
+		b.successes = 0
+	case CircuitClosed:
+		b.resetWindow(now)
+	}
+}
+
+func (b *circuitBreaker) takeChanges() []stateChange {
+	changes := b.changes
+	b.changes = nil
+	return changes
+}
+
+// notify calls OnStateChange for every change, it must be called without holding the lock
+// so that the callback can use the client.
+func (b *circuitBreaker) notify(changes []stateChange) {
+	if b.opts.OnStateChange == nil {
+		return
+	}
+	for _, c := range changes {
+		b.opts.OnStateChange(c.from, c.to)
+	}
+}
@@ -0,0 +1,203 @@
+package contentchef
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func newTestBreaker(o *CircuitBreakerOptions) (*circuitBreaker, *time.Time, *[]string) {
+	now := time.Now()
+	changes := []string{}
+	o.OnStateChange = func(from, to CircuitState) {
+		changes = append(changes, from.String()+"->"+to.String())
+	}
+	b := newCircuitBreaker(o)
+	b.now = func() time.Time { return now }
+	return b, &now, &changes
+}
+
+func Test_newCircuitBreaker(t *testing.T) {
+	if b := newCircuitBreaker(nil); b != nil {
+		t.Errorf("Expected no breaker without options, got %#v", b)
+	}
+	var b *circuitBreaker
+	if err := b.allow(); err != nil {
+		t.Errorf("allow() on a nil breaker returned error: %v", err)
+	}
+	b.record(true, 0)
+	b.ignore()
+
+	b = newCircuitBreaker(&CircuitBreakerOptions{})
+	want := CircuitBreakerOptions{
+		FailureRatio:   0.5,
+		MinRequests:    10,
+		Window:         time.Minute,
+		OpenTimeout:    30 * time.Second,
+		HalfOpenProbes: 1,
+	}
+	if !reflect.DeepEqual(b.opts, want) {
+		t.Errorf("newCircuitBreaker() options = %+v, want %+v", b.opts, want)
+	}
+}
+
+func TestCircuitBreaker_opensOnFailureRatio(t *testing.T) {
+	b, _, changes := newTestBreaker(&CircuitBreakerOptions{FailureRatio: 0.75, MinRequests: 4})
+
+	steps := []struct {
+		failed bool
+		want   CircuitState
+	}{
+		{true, CircuitClosed},
+		{true, CircuitClosed},
+		{true, CircuitClosed}, // below MinRequests
+		{false, CircuitOpen},
+	}
+	for i, step := range steps {
+		if err := b.allow(); err != nil {
+			t.Fatalf("allow() %d returned error: %v", i, err)
+		}
+		b.record(step.failed, 0)
+		if s := b.State(); s != step.want {
+			t.Fatalf("State() after request %d = %v, want %v", i, s, step.want)
+		}
+	}
+	if err := b.allow(); err != ErrCircuitOpen {
+		t.Errorf("allow() = %v, want %v", err, ErrCircuitOpen)
+	}
+	if want := []string{"closed->open"}; !reflect.DeepEqual(*changes, want) {
+		t.Errorf("OnStateChange calls = %v, want %v", *changes, want)
+	}
+}
+
+func TestCircuitBreaker_window(t *testing.T) {
+	b, now, _ := newTestBreaker(&CircuitBreakerOptions{MinRequests: 2, Window: time.Minute})
+
+	b.allow()
+	b.record(true, 0)
+	*now = now.Add(2 * time.Minute)
+	b.allow()
+	b.record(true, 0)
+	if s := b.State(); s != CircuitClosed {
+		t.Errorf("Expected failures of an elapsed window to be forgotten, got %v", s)
+	}
+}
+
+func TestCircuitBreaker_slowThreshold(t *testing.T) {
+	b, _, _ := newTestBreaker(&CircuitBreakerOptions{MinRequests: 2, SlowThreshold: time.Second})
+
+	for i := 0; i < 2; i++ {
+		b.allow()
+		b.record(false, 2*time.Second)
+	}
+	if s := b.State(); s != CircuitOpen {
+		t.Errorf("Expected slow requests to open the breaker, got %v", s)
+	}
+}
+
+func TestCircuitBreaker_halfOpen(t *testing.T) {
+	b, now, changes := newTestBreaker(&CircuitBreakerOptions{MinRequests: 1, OpenTimeout: time.Minute, HalfOpenProbes: 2})
+
+	b.allow()
+	b.record(true, 0)
+	*now = now.Add(time.Minute)
+
+	// the probes are let through, anything else fails fast
+	for i := 0; i < 2; i++ {
+		if err := b.allow(); err != nil {
+			t.Fatalf("allow() for probe %d returned error: %v", i, err)
+		}
+	}
+	if err := b.allow(); err != ErrCircuitOpen {
+		t.Fatalf("allow() over the probes = %v, want %v", err, ErrCircuitOpen)
+	}
+
+	// a probe failing opens the breaker again
+	b.record(true, 0)
+	if s := b.State(); s != CircuitOpen {
+		t.Fatalf("Expected a failed probe to open the breaker, got %v", s)
+	}
+
+	// ignored probes can be sent again, successful ones close the breaker
+	*now = now.Add(time.Minute)
+	b.allow()
+	b.ignore()
+	for i := 0; i < 2; i++ {
+		if err := b.allow(); err != nil {
+			t.Fatalf("allow() for probe %d returned error: %v", i, err)
+		}
+		b.record(false, 0)
+	}
+	if s := b.State(); s != CircuitClosed {
+		t.Fatalf("Expected successful probes to close the breaker, got %v", s)
+	}
+
+	want := []string{"closed->open", "open->half-open", "half-open->open", "open->half-open", "half-open->closed"}
+	if !reflect.DeepEqual(*changes, want) {
+		t.Errorf("OnStateChange calls = %v, want %v", *changes, want)
+	}
+}
+
+func TestDo_circuitBreaker(t *testing.T) {
+	setup()
+	defer teardown()
+	client.breaker = newCircuitBreaker(&CircuitBreakerOptions{MinRequests: 2})
+
+	calls := 0
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if r.URL.Query().Get("status") == "404" {
+			http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+			return
+		}
+		http.Error(w, `{"message":"unavailable"}`, http.StatusServiceUnavailable)
+	})
+
+	// client errors are not failures
+	for i := 0; i < 2; i++ {
+		req, _ := client.newRequest(http.MethodGet, "/?status=404", nil)
+		client.do(context.Background(), req, nil)
+	}
+	if s := client.CircuitState(); s != CircuitClosed {
+		t.Fatalf("Expected client errors to keep the breaker closed, got %v", s)
+	}
+
+	for i := 0; i < 2; i++ {
+		req, _ := client.newRequest(http.MethodGet, "/", nil)
+		client.do(context.Background(), req, nil)
+	}
+	if s := client.CircuitState(); s != CircuitOpen {
+		t.Fatalf("Expected server errors to open the breaker, got %v", s)
+	}
+
+	req, _ := client.newRequest(http.MethodGet, "/", nil)
+	_, err := client.do(context.Background(), req, nil)
+	if err != ErrCircuitOpen {
+		t.Errorf("do() = %v, want %v", err, ErrCircuitOpen)
+	}
+	if calls != 4 {
+		t.Errorf("Expected 4 calls to the API, got %d", calls)
+	}
+}
+
+func TestCircuitState_String(t *testing.T) {
+	tests := []struct {
+		state CircuitState
+		want  string
+	}{
+		{CircuitClosed, "closed"},
+		{CircuitOpen, "open"},
+		{CircuitHalfOpen, "half-open"},
+		{CircuitState(42), "unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(int(tt.state)), func(t *testing.T) {
+			if got := tt.state.String(); got != tt.want {
+				t.Errorf("String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
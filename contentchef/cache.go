@@ -0,0 +1,142 @@
+package contentchef
+
+import (
+	"container/list"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/go-querystring/query"
+)
+
+// Cache stores the raw body of Content and Search responses.
+//
+// Implementations must be safe for concurrent use.
+type Cache interface {
+	// Get returns the value stored for key, if any.
+	Get(key string) ([]byte, bool)
+	// Set stores value for key.
+	Set(key string, value []byte)
+	// Delete removes the value stored for key.
+	Delete(key string)
+	// Purge removes every stored value.
+	Purge()
+}
+
+// LRUCache is an in-memory Cache which holds at most a fixed number of entries,
+// evicting the least recently used one when full, and expires entries older than its TTL.
+type LRUCache struct {
+	mu    sync.Mutex
+	size  int
+	ttl   time.Duration
+	ll    *list.List
+	items map[string]*list.Element
+
+	now func() time.Time
+}
+
+type lruEntry struct {
+	key     string
+	value   []byte
+	expires time.Time
+}
+
+// NewLRUCache returns a new LRUCache reference.
+//
+// It takes the maximum number of entries to keep and how long an entry stays valid,
+// a size lower than 1 means no limit and a ttl of 0 means entries never expire.
+func NewLRUCache(size int, ttl time.Duration) *LRUCache {
+	return &LRUCache{
+		size:  size,
+		ttl:   ttl,
+		ll:    list.New(),
+		items: make(map[string]*list.Element),
+		now:   time.Now,
+	}
+}
+
+// Get returns the value stored for key if it is present and not expired.
+func (c *LRUCache) Get(key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.items[key]
+	if !ok {
+		return nil, false
+	}
+	entry := el.Value.(*lruEntry)
+	if !entry.expires.IsZero() && !c.now().Before(entry.expires) {
+		c.removeElement(el)
+		return nil, false
+	}
+	c.ll.MoveToFront(el)
+	return entry.value, true
+}
+
+// Set stores value for key, evicting the least recently used entry if the cache is full.
+func (c *LRUCache) Set(key string, value []byte) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var expires time.Time
+	if c.ttl > 0 {
+		expires = c.now().Add(c.ttl)
+	}
+	if el, ok := c.items[key]; ok {
+		entry := el.Value.(*lruEntry)
+		entry.value = value
+		entry.expires = expires
+		c.ll.MoveToFront(el)
+		return
+	}
+	c.items[key] = c.ll.PushFront(&lruEntry{key: key, value: value, expires: expires})
+	if c.size > 0 && c.ll.Len() > c.size {
+		c.removeElement(c.ll.Back())
+	}
+}
+
+// Delete removes the value stored for key.
+func (c *LRUCache) Delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if el, ok := c.items[key]; ok {
+		c.removeElement(el)
+	}
+}
+
+// Purge removes every stored value.
+func (c *LRUCache) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.ll.Init()
+	c.items = make(map[string]*list.Element)
+}
+
+// Len returns the number of entries in the cache, expired ones included.
+func (c *LRUCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.ll.Len()
+}
+
+func (c *LRUCache) removeElement(el *list.Element) {
+	c.ll.Remove(el)
+	delete(c.items, el.Value.(*lruEntry).key)
+}
+
+// cacheKey builds the key used to store a channel response.
+// The kind ("online" or "preview") is part of the key so entries of different channel kinds never mix.
+func cacheKey(kind, method, spaceID, channel, state string, targetDate time.Time, opts interface{}) (string, error) {
+	qs, err := query.Values(opts)
+	if err != nil {
+		return "", err
+	}
+	var date string
+	if !targetDate.IsZero() {
+		date = targetDate.UTC().Format(time.RFC3339)
+	}
+	return strings.Join([]string{kind, method, spaceID, channel, state, date, qs.Encode()}, "|"), nil
+}
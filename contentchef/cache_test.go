@@ -0,0 +1,185 @@
+package contentchef
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestLRUCache_evictsLeastRecentlyUsed(t *testing.T) {
+	c := NewLRUCache(2, 0)
+	c.Set("a", []byte("1"))
+	c.Set("b", []byte("2"))
+	c.Get("a")
+	c.Set("c", []byte("3"))
+
+	if _, ok := c.Get("b"); ok {
+		t.Errorf("Expected b to be evicted")
+	}
+	if v, ok := c.Get("a"); !ok || string(v) != "1" {
+		t.Errorf("Get(a) = %s, %v, want 1, true", v, ok)
+	}
+	if v, ok := c.Get("c"); !ok || string(v) != "3" {
+		t.Errorf("Get(c) = %s, %v, want 3, true", v, ok)
+	}
+	if c.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", c.Len())
+	}
+}
+
+func TestLRUCache_expiresEntries(t *testing.T) {
+	now := time.Now()
+	c := NewLRUCache(0, time.Minute)
+	c.now = func() time.Time { return now }
+	c.Set("a", []byte("1"))
+
+	if _, ok := c.Get("a"); !ok {
+		t.Errorf("Expected a to be cached")
+	}
+	now = now.Add(time.Minute)
+	if _, ok := c.Get("a"); ok {
+		t.Errorf("Expected a to be expired")
+	}
+	if c.Len() != 0 {
+		t.Errorf("Len() = %d, want 0", c.Len())
+	}
+}
+
+func TestLRUCache_DeleteAndPurge(t *testing.T) {
+	c := NewLRUCache(0, 0)
+	c.Set("a", []byte("1"))
+	c.Set("b", []byte("2"))
+
+	c.Delete("a")
+	if _, ok := c.Get("a"); ok {
+		t.Errorf("Expected a to be deleted")
+	}
+	c.Purge()
+	if _, ok := c.Get("b"); ok {
+		t.Errorf("Expected b to be purged")
+	}
+}
+
+func Test_cacheKey(t *testing.T) {
+	date, _ := time.Parse(time.RFC3339, "2020-04-09T22:00:00Z")
+	opts := &ContentOptions{PublicID: "foo"}
+
+	online, _ := cacheKey("online", "content", "aSpace", "aChannel", "", time.Time{}, opts)
+	live, _ := cacheKey("preview", "content", "aSpace", "aChannel", "live", time.Time{}, opts)
+	dated, _ := cacheKey("preview", "content", "aSpace", "aChannel", "live", date, opts)
+	other, _ := cacheKey("online", "content", "aSpace", "aChannel", "", time.Time{}, &ContentOptions{PublicID: "bar"})
+
+	want := "online|content|aSpace|aChannel|||publicId=foo"
+	if online != want {
+		t.Errorf("cacheKey() = %v, want %v", online, want)
+	}
+	keys := map[string]bool{online: true, live: true, dated: true, other: true}
+	if len(keys) != 4 {
+		t.Errorf("cacheKey() returned colliding keys: %v", keys)
+	}
+}
+
+func TestOnlineChannel_Content_cached(t *testing.T) {
+	setup()
+	defer teardown()
+	client.cache = NewLRUCache(10, time.Minute)
+
+	calls := 0
+	mux.HandleFunc("/space/my_space/online/content/aChannel", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		fmt.Fprint(w, `{"publicId": "foo"}`)
+	})
+
+	ch, _ := client.GetOnlineChannel("aChannel", "superSecret")
+	for i := 0; i < 2; i++ {
+		got, err := ch.Content(ctx, &ContentOptions{PublicID: "foo"})
+		if err != nil {
+			t.Fatalf("Content() returned error: %v", err)
+		}
+		if got.PublicID != "foo" {
+			t.Errorf("Content().PublicID = %v, want foo", got.PublicID)
+		}
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 request, got %d", calls)
+	}
+}
+
+func TestOnlineChannel_Search_staleIfError(t *testing.T) {
+	setup()
+	defer teardown()
+	now := time.Now()
+	stale := NewLRUCache(10, time.Minute)
+	stale.now = func() time.Time { return now }
+	client.stale = stale
+
+	status := http.StatusOK
+	mux.HandleFunc("/space/my_space/online/search/v2/aChannel", func(w http.ResponseWriter, r *http.Request) {
+		if status != http.StatusOK {
+			http.Error(w, `{"message":"failed"}`, status)
+			return
+		}
+		fmt.Fprint(w, `{"items": [{"publicId": "foo"}], "total": 1}`)
+	})
+
+	ch, _ := client.GetOnlineChannel("aChannel", "superSecret")
+	got, err := ch.Search(ctx, &SearchOptions{Take: 1})
+	if err != nil {
+		t.Fatalf("Search() returned error: %v", err)
+	}
+	if got.Stale {
+		t.Errorf("Expected a fresh response")
+	}
+
+	status = http.StatusInternalServerError
+	got, err = ch.Search(ctx, &SearchOptions{Take: 1})
+	if err != nil {
+		t.Fatalf("Search() returned error: %v", err)
+	}
+	if !got.Stale || got.Total != 1 || got.Items[0].PublicID != "foo" {
+		t.Errorf("Expected the last good response flagged as stale, got %+v", got)
+	}
+
+	// only the same request is served the stale response
+	if _, err := ch.Search(ctx, &SearchOptions{Take: 2}); !IsServerError(err) {
+		t.Errorf("Expected a server error for another request, got %v", err)
+	}
+
+	// client errors are returned as they are
+	status = http.StatusNotFound
+	if _, err := ch.Search(ctx, &SearchOptions{Take: 1}); !IsNotFound(err) {
+		t.Errorf("Expected a not found error, got %v", err)
+	}
+
+	// responses older than the maximum staleness are not served
+	status = http.StatusServiceUnavailable
+	now = now.Add(2 * time.Minute)
+	if _, err := ch.Search(ctx, &SearchOptions{Take: 1}); !IsServerError(err) {
+		t.Errorf("Expected a server error once the response expired, got %v", err)
+	}
+}
+
+func TestOnlineChannel_Content_staleOnNetworkError(t *testing.T) {
+	setup()
+	defer teardown()
+	client.stale = NewLRUCache(10, time.Minute)
+
+	mux.HandleFunc("/space/my_space/online/content/aChannel", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"publicId": "foo"}`)
+	})
+
+	ch, _ := client.GetOnlineChannel("aChannel", "superSecret")
+	if _, err := ch.Content(ctx, &ContentOptions{PublicID: "foo"}); err != nil {
+		t.Fatalf("Content() returned error: %v", err)
+	}
+
+	server.Close()
+	got, err := ch.Content(ctx, &ContentOptions{PublicID: "foo"})
+	if err != nil {
+		t.Fatalf("Content() returned error: %v", err)
+	}
+	if !got.Stale || got.PublicID != "foo" {
+		t.Errorf("Expected the last good response flagged as stale, got %+v", got)
+	}
+}
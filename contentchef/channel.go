@@ -20,6 +20,28 @@ type Response struct {
 	OfflineDate    time.Time      `json:"offlineDate"`
 	Metadata       Metadata       `json:"metadata"`
 	RequestContext RequestContext `json:"requestContext"`
+	// Stale is true when the request failed and the last good response was served instead
+	Stale bool `json:"-"`
+}
+
+// RawResponse is a ContentChef content whose payload is kept undecoded,
+// so that it can be decoded into a Go value with DecodePayload.
+type RawResponse struct {
+	PublicID       string          `json:"publicId"`
+	Definition     string          `json:"definition"`
+	Repository     string          `json:"repository"`
+	Payload        json.RawMessage `json:"payload"`
+	OnlineDate     time.Time       `json:"onlineDate"`
+	OfflineDate    time.Time       `json:"offlineDate"`
+	Metadata       Metadata        `json:"metadata"`
+	RequestContext RequestContext  `json:"requestContext"`
+	// Stale is true when the request failed and the last good response was served instead
+	Stale bool `json:"-"`
+}
+
+// DecodePayload decodes the content's payload into the value pointed to by v.
+func (r *RawResponse) DecodePayload(v interface{}) error {
+	return json.Unmarshal(r.Payload, v)
 }
 
 type Metadata struct {
@@ -37,6 +59,19 @@ type PaginatedResponse struct {
 	Skip           int            `json:"skip"`
 	Take           int            `json:"take"`
 	RequestContext RequestContext `json:"requestContext"`
+	// Stale is true when the request failed and the last good response was served instead
+	Stale bool `json:"-"`
+}
+
+// RawPaginatedResponse is a page of search results whose payloads are kept undecoded.
+type RawPaginatedResponse struct {
+	Items          []RawResponse  `json:"items"`
+	Total          int            `json:"total"`
+	Skip           int            `json:"skip"`
+	Take           int            `json:"take"`
+	RequestContext RequestContext `json:"requestContext"`
+	// Stale is true when the request failed and the last good response was served instead
+	Stale bool `json:"-"`
 }
 
 type RequestContext struct {
@@ -50,6 +85,8 @@ type ContentOptions struct {
 	LegacyMetadata bool `url:"legacyMetadata,omitempty"`
 	// The publicId of the content you want to retrieve
 	PublicID string `url:"publicId"`
+	// TargetDate overrides the client's TargetDate in preview channels, it is ignored by online channels
+	TargetDate time.Time `url:"-"`
 }
 
 // SearchOptions specifies the parameters to the Channel's and Online Channel's Search method.
@@ -70,6 +107,8 @@ type SearchOptions struct {
 	PropFilters PropFilters `url:"propFilters,omitempty"`
 	// How you want to sort your content
 	Sorting Sorting `url:"sorting,omitempty"`
+	// TargetDate overrides the client's TargetDate in preview channels, it is ignored by online channels
+	TargetDate time.Time `url:"-"`
 }
 
 type SortingField struct {
@@ -104,7 +143,7 @@ type PropFilters struct {
 	// The logical operator you want to apply.
 	// Possible values:
 	// AND, OR
-	Condition string           `json:"condition,omitempty"`
+	Condition Condition        `json:"condition,omitempty"`
 	Items     []PropFilterItem `json:"items,omitempty"`
 }
 
@@ -113,7 +152,7 @@ type PropFilterItem struct {
 	// The operator you want to apply.
 	// Possible values:
 	// CONTAINS, CONTAINS_IC, EQUALS, EQUALS_IC, IN, IN_IC, STARTS_WITH, STARTS_WITH_IC
-	Operator string      `json:"operator,omitempty"`
+	Operator Operator    `json:"operator,omitempty"`
 	Value    interface{} `json:"value,omitempty"`
 }
 
@@ -121,6 +160,10 @@ func (p PropFilters) EncodeValues(key string, v *url.Values) error {
 	if len(p.Items) == 0 {
 		return nil
 	}
+	err := p.Validate()
+	if err != nil {
+		return err
+	}
 	j, err := json.Marshal(p)
 	if err != nil {
 		return err
@@ -161,13 +204,38 @@ func (c *Client) GetOnlineChannel(name, apiKey string) (*OnlineChannel, error) {
 // It takes a context and a a reference to a ContentOptions struct
 // if you are not sure about the context to use, use context.TODO()
 func (s *OnlineChannel) Content(ctx context.Context, config *ContentOptions) (*Response, error) {
-	path := getOnlineEndpoint(s.client.SpaceID, "content", s.name)
-
 	r := &Response{}
-	err := s.client.get(ctx, path, s.apiKey, config, r)
+	stale, err := s.content(ctx, config, r)
+	r.Stale = stale
 	return r, err
 }
 
+// ContentRaw works like Content but keeps the content's payload undecoded.
+func (s *OnlineChannel) ContentRaw(ctx context.Context, config *ContentOptions) (*RawResponse, error) {
+	r := &RawResponse{}
+	stale, err := s.content(ctx, config, r)
+	r.Stale = stale
+	return r, err
+}
+
+func (s *OnlineChannel) content(ctx context.Context, config *ContentOptions, v interface{}) (bool, error) {
+	path := getOnlineEndpoint(s.client.SpaceID, "content", s.name)
+	key, err := cacheKey("online", "content", s.client.SpaceID, s.name, "", time.Time{}, config)
+	if err != nil {
+		return false, err
+	}
+
+	var publicID string
+	if config != nil {
+		publicID = config.PublicID
+	}
+	ctx = withRequestInfo(ctx, RequestInfo{Channel: s.name, Operation: OperationContent})
+	ctx, end := s.client.startSpan(ctx, Attribute{AttributePublicID, publicID})
+	stale, err := s.client.getCached(ctx, key, path, s.apiKey, config, v)
+	end(stale, err)
+	return stale, err
+}
+
 // Search returns a PaginatedResponse reference.
 // It will retrieve for a range of contents using multiple matching criteria
 // like content definition name, publishing dates and more.
@@ -175,12 +243,32 @@ func (s *OnlineChannel) Content(ctx context.Context, config *ContentOptions) (*R
 // It takes a context and a a reference to a SearchOptions struct
 // if you are not sure about the context to use, use context.TODO()
 func (s *OnlineChannel) Search(ctx context.Context, config *SearchOptions) (*PaginatedResponse, error) {
+	r := &PaginatedResponse{}
+	stale, err := s.search(ctx, config, r)
+	r.Stale = stale
+	return r, err
+}
+
+// SearchRaw works like Search but keeps the contents' payloads undecoded.
+func (s *OnlineChannel) SearchRaw(ctx context.Context, config *SearchOptions) (*RawPaginatedResponse, error) {
+	r := &RawPaginatedResponse{}
+	stale, err := s.search(ctx, config, r)
+	r.Stale = stale
+	return r, err
+}
 
+func (s *OnlineChannel) search(ctx context.Context, config *SearchOptions, v interface{}) (bool, error) {
 	path := getOnlineEndpoint(s.client.SpaceID, "search/v2", s.name)
+	key, err := cacheKey("online", "search", s.client.SpaceID, s.name, "", time.Time{}, config)
+	if err != nil {
+		return false, err
+	}
 
-	r := &PaginatedResponse{}
-	err := s.client.get(ctx, path, s.apiKey, config, r)
-	return r, err
+	ctx = withRequestInfo(ctx, RequestInfo{Channel: s.name, Operation: OperationSearch})
+	ctx, end := s.client.startSpan(ctx, Attribute{AttributeSearch, searchSummary(config)})
+	stale, err := s.client.getCached(ctx, key, path, s.apiKey, config, v)
+	end(stale, err)
+	return stale, err
 }
 
 func getOnlineEndpoint(spaceID string, method, channel string) string {
@@ -226,11 +314,27 @@ func (c *Client) GetPreviewChannel(name, apiKey, state string) (*PreviewChannel,
 // It takes a context and a a reference to a ContentOptions struct
 // if you are not sure about the context to use, use context.TODO()
 func (s *PreviewChannel) Content(ctx context.Context, config *ContentOptions) (*Response, error) {
+	r := &Response{}
+	stale, err := s.content(ctx, config, r)
+	r.Stale = stale
+	return r, err
+}
+
+// ContentRaw works like Content but keeps the content's payload undecoded.
+func (s *PreviewChannel) ContentRaw(ctx context.Context, config *ContentOptions) (*RawResponse, error) {
+	r := &RawResponse{}
+	stale, err := s.content(ctx, config, r)
+	r.Stale = stale
+	return r, err
+}
+
+func (s *PreviewChannel) content(ctx context.Context, config *ContentOptions, v interface{}) (bool, error) {
 	path := getPreviewEndpoint(s.client.SpaceID, "content", s.name, s.state)
 
+	date := s.targetDate(config.TargetDate)
 	var targetDate string
-	if !s.client.TargetDate.IsZero() {
-		targetDate = s.client.TargetDate.Format(time.RFC3339)
+	if !date.IsZero() {
+		targetDate = date.Format(time.RFC3339)
 	}
 	urlParams := struct {
 		ContentOptions
@@ -240,9 +344,16 @@ func (s *PreviewChannel) Content(ctx context.Context, config *ContentOptions) (*
 		targetDate,
 	}
 
-	r := &Response{}
-	err := s.client.get(ctx, path, s.apiKey, urlParams, r)
-	return r, err
+	key, err := cacheKey("preview", "content", s.client.SpaceID, s.name, s.state, date, config)
+	if err != nil {
+		return false, err
+	}
+
+	ctx = withRequestInfo(ctx, RequestInfo{Channel: s.name, Operation: OperationContent, Preview: true, State: s.state})
+	ctx, end := s.client.startSpan(ctx, Attribute{AttributeState, s.state}, Attribute{AttributePublicID, config.PublicID})
+	stale, err := s.client.getCached(ctx, key, path, s.apiKey, urlParams, v)
+	end(stale, err)
+	return stale, err
 }
 
 // Search returns a PaginatedResponse reference.
@@ -252,11 +363,27 @@ func (s *PreviewChannel) Content(ctx context.Context, config *ContentOptions) (*
 // It takes a context and a a reference to a SearchOptions struct
 // if you are not sure about the context to use, use context.TODO()
 func (s *PreviewChannel) Search(ctx context.Context, config *SearchOptions) (*PaginatedResponse, error) {
+	r := &PaginatedResponse{}
+	stale, err := s.search(ctx, config, r)
+	r.Stale = stale
+	return r, err
+}
+
+// SearchRaw works like Search but keeps the contents' payloads undecoded.
+func (s *PreviewChannel) SearchRaw(ctx context.Context, config *SearchOptions) (*RawPaginatedResponse, error) {
+	r := &RawPaginatedResponse{}
+	stale, err := s.search(ctx, config, r)
+	r.Stale = stale
+	return r, err
+}
+
+func (s *PreviewChannel) search(ctx context.Context, config *SearchOptions, v interface{}) (bool, error) {
 	path := getPreviewEndpoint(s.client.SpaceID, "search/v2", s.name, s.state)
 
+	date := s.targetDate(config.TargetDate)
 	var targetDate string
-	if !s.client.TargetDate.IsZero() {
-		targetDate = s.client.TargetDate.Format(time.RFC3339)
+	if !date.IsZero() {
+		targetDate = date.Format(time.RFC3339)
 	}
 	urlParams := struct {
 		SearchOptions
@@ -266,9 +393,24 @@ func (s *PreviewChannel) Search(ctx context.Context, config *SearchOptions) (*Pa
 		targetDate,
 	}
 
-	r := &PaginatedResponse{}
-	err := s.client.get(ctx, path, s.apiKey, urlParams, r)
-	return r, err
+	key, err := cacheKey("preview", "search", s.client.SpaceID, s.name, s.state, date, config)
+	if err != nil {
+		return false, err
+	}
+
+	ctx = withRequestInfo(ctx, RequestInfo{Channel: s.name, Operation: OperationSearch, Preview: true, State: s.state})
+	ctx, end := s.client.startSpan(ctx, Attribute{AttributeState, s.state}, Attribute{AttributeSearch, searchSummary(config)})
+	stale, err := s.client.getCached(ctx, key, path, s.apiKey, urlParams, v)
+	end(stale, err)
+	return stale, err
+}
+
+// targetDate returns the date contents are previewed at, override if set or the client's TargetDate.
+func (s *PreviewChannel) targetDate(override time.Time) time.Time {
+	if !override.IsZero() {
+		return override
+	}
+	return s.client.TargetDate
 }
 
 func getPreviewEndpoint(spaceID string, method, channel, state string) string {
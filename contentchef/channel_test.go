@@ -1,6 +1,8 @@
 package contentchef
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"net/url"
 	"reflect"
@@ -258,3 +260,110 @@ func TestClient_GetPreviewChannel(t *testing.T) {
 		})
 	}
 }
+
+func TestOnlineChannel_ContentRaw(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/space/my_space/online/content/aChannel", func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("publicId"); got != "foo" {
+			t.Errorf("publicId = %v, want foo", got)
+		}
+		fmt.Fprint(w, `{"publicId": "foo", "definition": "article", "payload": {"title": "Hello", "views": 3}}`)
+	})
+
+	ch, _ := client.GetOnlineChannel("aChannel", "superSecret")
+	got, err := ch.ContentRaw(context.TODO(), &ContentOptions{PublicID: "foo"})
+	if err != nil {
+		t.Fatalf("ContentRaw() returned error: %v", err)
+	}
+
+	type article struct {
+		Title string `json:"title"`
+		Views int    `json:"views"`
+	}
+	var a article
+	if err := got.DecodePayload(&a); err != nil {
+		t.Fatalf("DecodePayload() returned error: %v", err)
+	}
+	want := article{Title: "Hello", Views: 3}
+	if got.PublicID != "foo" || a != want {
+		t.Errorf("ContentRaw() = %v with payload %v, want foo with payload %v", got.PublicID, a, want)
+	}
+}
+
+func TestPreviewChannel_SearchRaw(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/space/my_space/preview/staging/search/v2/aChannel", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("targetDate") == "" {
+			t.Errorf("Expected targetDate to be sent")
+		}
+		fmt.Fprint(w, `{"items": [{"publicId": "foo", "payload": {"title": "Foo"}}, {"publicId": "bar", "payload": {"title": "Bar"}}], "total": 2, "take": 10}`)
+	})
+
+	ch, _ := client.GetPreviewChannel("aChannel", "superSecret", "staging")
+	got, err := ch.SearchRaw(context.TODO(), &SearchOptions{Take: 10})
+	if err != nil {
+		t.Fatalf("SearchRaw() returned error: %v", err)
+	}
+	if got.Total != 2 || len(got.Items) != 2 {
+		t.Fatalf("SearchRaw() returned %d of %d items, want 2 of 2", len(got.Items), got.Total)
+	}
+
+	var titles []string
+	for _, item := range got.Items {
+		var payload struct {
+			Title string `json:"title"`
+		}
+		if err := item.DecodePayload(&payload); err != nil {
+			t.Fatalf("DecodePayload() returned error: %v", err)
+		}
+		titles = append(titles, payload.Title)
+	}
+	if want := []string{"Foo", "Bar"}; !reflect.DeepEqual(titles, want) {
+		t.Errorf("Decoded titles = %v, want %v", titles, want)
+	}
+}
+
+func TestPreviewChannel_targetDate(t *testing.T) {
+	setup()
+	defer teardown()
+	client.cache = NewLRUCache(10, time.Minute)
+
+	mux.HandleFunc("/space/my_space/preview/live/content/aChannel", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"publicId": %q}`, r.URL.Query().Get("targetDate"))
+	})
+
+	clientDate := client.TargetDate.Format(time.RFC3339)
+	override := time.Date(2020, 4, 9, 22, 0, 0, 0, time.UTC)
+	copyDate := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		client *Client
+		config *ContentOptions
+		want   string
+	}{
+		{"client date", client, &ContentOptions{PublicID: "foo"}, clientDate},
+		{"options override", client, &ContentOptions{PublicID: "foo", TargetDate: override}, "2020-04-09T22:00:00Z"},
+		{"client copy", client.WithTargetDate(copyDate), &ContentOptions{PublicID: "foo"}, "2021-01-01T00:00:00Z"},
+		{"client date again", client, &ContentOptions{PublicID: "foo"}, clientDate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch, _ := tt.client.GetPreviewChannel("aChannel", "superSecret", "live")
+			got, err := ch.Content(ctx, tt.config)
+			if err != nil {
+				t.Fatalf("Content() returned error: %v", err)
+			}
+			if got.PublicID != tt.want {
+				t.Errorf("targetDate = %v, want %v", got.PublicID, tt.want)
+			}
+		})
+	}
+	if client.TargetDate.Format(time.RFC3339) != clientDate {
+		t.Errorf("Expected WithTargetDate to leave the client untouched")
+	}
+}
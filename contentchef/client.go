@@ -5,7 +5,6 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
@@ -20,11 +19,24 @@ const (
 	libraryVersion = "1.0.3"
 	userAgent      = "contentchef-go/" + libraryVersion
 	mediaType      = "application/json"
+
+	// maxStaleEntries is the number of requests whose last good response is kept for StaleIfError
+	maxStaleEntries = 1000
 )
 
 // Client manages the communication with the ContenChef API.
 type Client struct {
 	httpClient *http.Client
+	cache      Cache
+	retry      *RetryOptions
+	coalescer  *coalescer
+	limiter    *limiter
+	breaker    *circuitBreaker
+	stale      Cache
+	onRequest  func(req *http.Request, info RequestInfo)
+	onResponse func(info ResponseInfo)
+	tracer     Tracer
+	metrics    *Metrics
 	BaseURL    *url.URL
 	SpaceID    string
 	TargetDate time.Time
@@ -40,6 +52,36 @@ type ClientOptions struct {
 	Client *http.Client
 	// TargetDate is used to retrieve contents in the preview channel in a specific dare different from the current date
 	TargetDate time.Time
+	// Cache is used to store Content and Search responses, if nil responses are not cached
+	Cache Cache
+	// Retry configures how failed requests are retried, if nil requests are not retried
+	Retry *RetryOptions
+	// CoalesceRequests collapses concurrent identical requests into a single one,
+	// whose response is handed to every caller
+	CoalesceRequests bool
+	// RateLimit is the maximum number of requests per second, if 0 the rate is not limited
+	RateLimit float64
+	// RateBurst is the number of requests which can be sent at once before RateLimit kicks in, defaults to 1
+	RateBurst int
+	// MaxConcurrentRequests is the maximum number of requests in flight, if 0 it is not limited
+	MaxConcurrentRequests int
+	// CircuitBreaker configures the circuit breaker, if nil it is disabled
+	CircuitBreaker *CircuitBreakerOptions
+	// StaleIfError is the maximum age of the last good response of a request which is served,
+	// flagged as stale, when the request fails with a server or network error.
+	// If 0 failed requests are not served stale responses
+	StaleIfError time.Duration
+	// OnRequest is called before every request is sent, it can change the request headers
+	OnRequest func(req *http.Request, info RequestInfo)
+	// OnResponse is called once the response of every request sent has been read,
+	// or when no response was received
+	OnResponse func(info ResponseInfo)
+	// Transports wrap the transport of the HTTP client, the first one being the outermost
+	Transports []TransportMiddleware
+	// Tracer starts a span for every channel call, if nil calls are not traced
+	Tracer Tracer
+	// Metrics collects the metrics of the client's requests, if nil they are not collected
+	Metrics *Metrics
 }
 
 // NewClient return a new Client reference
@@ -57,15 +99,63 @@ func NewClient(o *ClientOptions) (*Client, error) {
 	if httpClient == nil {
 		httpClient = http.DefaultClient
 	}
+	if len(o.Transports) > 0 {
+		// the client is copied so that the one passed in the options is left untouched
+		wrapped := *httpClient
+		wrapped.Transport = chainTransport(httpClient.Transport, o.Transports)
+		httpClient = &wrapped
+	}
 	cf := &Client{
-		httpClient,
-		BaseURL,
-		o.SpaceID,
-		o.TargetDate,
+		httpClient: httpClient,
+		cache:      o.Cache,
+		retry:      o.Retry,
+		limiter:    newLimiter(o.RateLimit, o.RateBurst, o.MaxConcurrentRequests),
+		breaker:    newCircuitBreaker(o.CircuitBreaker),
+		onRequest:  o.OnRequest,
+		onResponse: o.OnResponse,
+		tracer:     o.Tracer,
+		metrics:    o.Metrics,
+		BaseURL:    BaseURL,
+		SpaceID:    o.SpaceID,
+		TargetDate: o.TargetDate,
+	}
+	if o.CoalesceRequests {
+		cf.coalescer = newCoalescer()
+	}
+	if o.Metrics != nil {
+		onResponse := o.OnResponse
+		cf.onResponse = func(info ResponseInfo) {
+			o.Metrics.observeResponse(info)
+			if onResponse != nil {
+				onResponse(info)
+			}
+		}
+	}
+	if o.StaleIfError > 0 {
+		cf.stale = NewLRUCache(maxStaleEntries, o.StaleIfError)
 	}
 	return cf, nil
 }
 
+// WithTargetDate returns a copy of the client previewing contents at t.
+//
+// The copy shares the cache, the limits and the other settings of c, which is left untouched,
+// so that different dates can be previewed concurrently.
+func (c *Client) WithTargetDate(t time.Time) *Client {
+	cp := *c
+	cp.TargetDate = t
+	return &cp
+}
+
+// CircuitState returns the state of the client's circuit breaker,
+// which is always CircuitClosed if the breaker is disabled.
+func (c *Client) CircuitState() CircuitState {
+	if c.breaker == nil {
+		return CircuitClosed
+	}
+	return c.breaker.State()
+}
+
 func (c *Client) newRequest(method, urlStr string, body interface{}) (*http.Request, error) {
 	u, err := c.BaseURL.Parse(urlStr)
 	if err != nil {
@@ -110,13 +200,8 @@ func (c *Client) do(ctx context.Context, req *http.Request, v interface{}) (*res
 	}
 
 	req = req.WithContext(ctx)
-	res, err := c.httpClient.Do(req)
+	res, err := c.send(ctx, req)
 	if err != nil {
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		default:
-		}
 		return nil, err
 	}
 	defer res.Body.Close()
@@ -137,7 +222,7 @@ func (c *Client) do(ctx context.Context, req *http.Request, v interface{}) (*res
 				decErr = nil
 			}
 			if decErr != nil {
-				err = decErr
+				err = &DecodeError{URL: req.URL.String(), Err: decErr}
 			}
 		}
 	}
@@ -145,31 +230,113 @@ func (c *Client) do(ctx context.Context, req *http.Request, v interface{}) (*res
 	return response, err
 }
 
-type errorResponse struct {
-	Response *http.Response
-	Message  string
-	X        map[string]interface{} `json:"-"`
+// send sends req, retrying it as configured in the client's RetryOptions.
+// Every attempt goes through the client's circuit breaker, waits for its rate and concurrency limits
+// and is reported to the client's hooks.
+func (c *Client) send(ctx context.Context, req *http.Request) (*http.Response, error) {
+	info, _ := RequestInfoFromContext(ctx)
+	for attempt := 1; ; attempt++ {
+		err := c.breaker.allow()
+		if err != nil {
+			return nil, err
+		}
+		release, err := c.limiter.acquire(ctx)
+		if err != nil {
+			c.breaker.ignore()
+			return nil, err
+		}
+		info.Attempt = attempt
+		attemptReq := req.WithContext(withRequestInfo(ctx, info))
+		if c.tracer != nil {
+			c.tracer.Inject(ctx, attemptReq.Header)
+		}
+		if c.onRequest != nil {
+			c.onRequest(attemptReq, info)
+		}
+		start := time.Now()
+		res, err := c.httpClient.Do(attemptReq)
+		latency := time.Since(start)
+		if err != nil {
+			release()
+			if c.onResponse != nil {
+				c.onResponse(ResponseInfo{RequestInfo: info, Request: attemptReq, Latency: latency, Err: err})
+			}
+			recordAttempt(ctx, attempt, 0)
+			select {
+			case <-ctx.Done():
+				c.breaker.ignore()
+				return nil, ctx.Err()
+			default:
+			}
+			c.breaker.record(true, latency)
+		} else {
+			c.breaker.record(res.StatusCode >= 500, latency)
+			recordAttempt(ctx, attempt, res.StatusCode)
+			res.Body = &releaseOnClose{ReadCloser: res.Body, release: release}
+			if c.onResponse != nil {
+				res.Body = c.reportOnClose(res, info, start)
+			}
+		}
+
+		delay, retry := c.retry.next(attempt, res, err)
+		if !retry {
+			return res, err
+		}
+		if res != nil {
+			io.Copy(ioutil.Discard, res.Body)
+			res.Body.Close()
+		}
+		err = sleep(ctx, delay)
+		if err != nil {
+			return nil, err
+		}
+		if req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+	}
 }
 
-func (r *errorResponse) Error() string {
-	return fmt.Sprintf("%v %v: %d %v",
-		r.Response.Request.Method, r.Response.Request.URL,
-		r.Response.StatusCode, r.Message)
+// reportOnClose wraps the body of res so that it is reported to the OnResponse hook once it is closed.
+func (c *Client) reportOnClose(res *http.Response, info RequestInfo, start time.Time) io.ReadCloser {
+	return &countingBody{
+		ReadCloser: res.Body,
+		onClose: func(n int64) {
+			c.onResponse(ResponseInfo{
+				RequestInfo: info,
+				Request:     res.Request,
+				StatusCode:  res.StatusCode,
+				Latency:     time.Since(start),
+				Bytes:       n,
+			})
+		},
+	}
 }
 
 func checkResponse(r *http.Response) error {
 	if c := r.StatusCode; c >= 200 && c <= 299 {
 		return nil
 	}
-	errorResponse := &errorResponse{Response: r}
+	apiErr := &APIError{StatusCode: r.StatusCode}
+	if r.Request != nil {
+		apiErr.Method = r.Request.Method
+		if r.Request.URL != nil {
+			apiErr.URL = r.Request.URL.String()
+		}
+	}
 	data, err := ioutil.ReadAll(r.Body)
 	if err == nil && len(data) > 0 {
-		err := json.Unmarshal(data, errorResponse)
+		err := json.Unmarshal(data, &apiErr.Fields)
 		if err != nil {
-			errorResponse.Message = string(data)
+			apiErr.Message = string(data)
+		} else if message, ok := apiErr.Fields["message"].(string); ok {
+			apiErr.Message = message
 		}
 	}
-	return errorResponse
+	return apiErr
 }
 
 func addOptions(path string, opts interface{}) (string, error) {
@@ -204,7 +371,80 @@ func (c *Client) get(ctx context.Context, path, apiKey string, opts, v interface
 	}
 	req.Header.Set("X-Chef-Key", apiKey)
 
-	_, err = c.do(ctx, req, v)
+	if c.coalescer == nil {
+		_, err = c.do(ctx, req, v)
+		return err
+	}
+	if ctx == nil {
+		return errors.New("context must be non-nil")
+	}
+
+	key := http.MethodGet + " " + req.URL.String() + " " + apiKey
+	data, err := c.coalescer.do(ctx, key, func(ctx context.Context) ([]byte, error) {
+		buf := new(bytes.Buffer)
+		_, err := c.do(ctx, req, buf)
+		return buf.Bytes(), err
+	})
+	if err != nil {
+		return err
+	}
+	return decode(data, v, req.URL.String())
+}
 
-	return err
+// decode stores data in the value pointed to by v, v can also be an io.Writer data is written to.
+func decode(data []byte, v interface{}, url string) error {
+	if v == nil || len(data) == 0 {
+		return nil
+	}
+	if w, ok := v.(io.Writer); ok {
+		_, err := w.Write(data)
+		return err
+	}
+	err := json.Unmarshal(data, v)
+	if err != nil {
+		return &DecodeError{URL: url, Err: err}
+	}
+	return nil
+}
+
+// getCached works like get but looks for the response in the client's Cache first,
+// storing it there when it has to be fetched.
+//
+// When the request fails with a server or network error and the client keeps stale responses,
+// the last good one is decoded instead and getCached reports it as stale.
+func (c *Client) getCached(ctx context.Context, key, path, apiKey string, opts, v interface{}) (bool, error) {
+	if c.cache == nil && c.stale == nil {
+		return false, c.get(ctx, path, apiKey, opts, v)
+	}
+	if c.cache != nil {
+		data, ok := c.cache.Get(key)
+		c.metrics.observeCache(ctx, ok)
+		if ok {
+			return false, decode(data, v, path)
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	err := c.get(ctx, path, apiKey, opts, buf)
+	if err != nil {
+		if c.stale != nil && isUpstreamFailure(err) {
+			if data, ok := c.stale.Get(key); ok {
+				c.metrics.observeStale(ctx)
+				return true, decode(data, v, path)
+			}
+		}
+		return false, err
+	}
+	data := buf.Bytes()
+	err = decode(data, v, path)
+	if err != nil {
+		return false, err
+	}
+	if c.cache != nil {
+		c.cache.Set(key, data)
+	}
+	if c.stale != nil {
+		c.stale.Set(key, data)
+	}
+	return false, nil
 }
@@ -111,15 +111,21 @@ func TestCheckResponse(t *testing.T) {
 		Body: ioutil.NopCloser(strings.NewReader(`{"message":"m",
 			"errors": [{"resource": "r", "field": "f", "code": "c"}]}`)),
 	}
-	err := checkResponse(res).(*errorResponse)
+	err := checkResponse(res).(*APIError)
 
 	if err == nil {
 		t.Fatalf("Expected error response.")
 	}
 
-	expected := &errorResponse{
-		Response: res,
-		Message:  "m",
+	expected := &APIError{
+		StatusCode: http.StatusBadRequest,
+		Message:    "m",
+		Fields: map[string]interface{}{
+			"message": "m",
+			"errors": []interface{}{
+				map[string]interface{}{"resource": "r", "field": "f", "code": "c"},
+			},
+		},
 	}
 	if !reflect.DeepEqual(err, expected) {
 		t.Errorf("Error = %#v, expected %#v", err, expected)
@@ -134,14 +140,14 @@ func TestCheckResponse_noBody(t *testing.T) {
 		StatusCode: http.StatusBadRequest,
 		Body:       ioutil.NopCloser(strings.NewReader("")),
 	}
-	err := checkResponse(res).(*errorResponse)
+	err := checkResponse(res).(*APIError)
 
 	if err == nil {
 		t.Errorf("Expected error response.")
 	}
 
-	expected := &errorResponse{
-		Response: res,
+	expected := &APIError{
+		StatusCode: http.StatusBadRequest,
 	}
 	if !reflect.DeepEqual(err, expected) {
 		t.Errorf("Error = %#v, expected %#v", err, expected)
@@ -158,25 +164,45 @@ func TestCheckResponse_errOnBodyClose(t *testing.T) {
 		StatusCode: http.StatusBadRequest,
 		Body:       ioutil.NopCloser(strings.NewReader("")),
 	}
-	err := checkResponse(res).(*errorResponse)
+	err := checkResponse(res).(*APIError)
 
 	if err == nil {
 		t.Errorf("Expected error response.")
 	}
 
-	expected := &errorResponse{
-		Response: res,
+	expected := &APIError{
+		StatusCode: http.StatusBadRequest,
+	}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("Error = %#v, expected %#v", err, expected)
+	}
+}
+
+// ensure that bodies which are not JSON objects are used as the message
+func TestCheckResponse_plainBody(t *testing.T) {
+	u, _ := url.Parse("https://example.com/foo")
+	res := &http.Response{
+		Request:    &http.Request{Method: http.MethodGet, URL: u},
+		StatusCode: http.StatusNotFound,
+		Body:       ioutil.NopCloser(strings.NewReader("Not Found")),
+	}
+	err := checkResponse(res).(*APIError)
+
+	expected := &APIError{
+		StatusCode: http.StatusNotFound,
+		Method:     http.MethodGet,
+		URL:        "https://example.com/foo",
+		Message:    "Not Found",
 	}
 	if !reflect.DeepEqual(err, expected) {
 		t.Errorf("Error = %#v, expected %#v", err, expected)
 	}
 }
 
-func Test_errorResponse_Error(t *testing.T) {
-	res := &http.Response{Request: &http.Request{}}
-	err := errorResponse{Message: "m", Response: res}
+func Test_APIError_Error(t *testing.T) {
+	err := APIError{Message: "m", StatusCode: http.StatusBadRequest}
 	if err.Error() == "" {
-		t.Errorf("Expected non-empty errorResponse.Error()")
+		t.Errorf("Expected non-empty APIError.Error()")
 	}
 }
 
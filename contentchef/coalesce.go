@@ -0,0 +1,81 @@
+package contentchef
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// coalescer collapses concurrent calls sharing the same key into a single one.
+//
+// Every caller waits for the shared call under its own context. A caller giving up does not
+// abort the shared call, which is canceled only when every caller waiting for it has gone.
+type coalescer struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+type call struct {
+	done    chan struct{}
+	data    []byte
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+func newCoalescer() *coalescer {
+	return &coalescer{calls: make(map[string]*call)}
+}
+
+// do calls fn, unless a call with the same key is already in flight, and returns its result.
+// The data returned is shared between callers and must not be modified.
+func (g *coalescer) do(ctx context.Context, key string, fn func(ctx context.Context) ([]byte, error)) ([]byte, error) {
+	g.mu.Lock()
+	c, ok := g.calls[key]
+	if !ok {
+		shared, cancel := context.WithCancel(detachedContext{ctx})
+		c = &call{done: make(chan struct{}), cancel: cancel}
+		g.calls[key] = c
+		go func() {
+			c.data, c.err = fn(shared)
+			cancel()
+			g.forget(key, c)
+			close(c.done)
+		}()
+	}
+	c.waiters++
+	g.mu.Unlock()
+
+	select {
+	case <-c.done:
+		return c.data, c.err
+	case <-ctx.Done():
+		g.mu.Lock()
+		c.waiters--
+		if c.waiters == 0 {
+			c.cancel()
+			if g.calls[key] == c {
+				delete(g.calls, key)
+			}
+		}
+		g.mu.Unlock()
+		return nil, ctx.Err()
+	}
+}
+
+func (g *coalescer) forget(key string, c *call) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.calls[key] == c {
+		delete(g.calls, key)
+	}
+}
+
+// detachedContext carries the values of its parent context but it is never canceled.
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
@@ -0,0 +1,176 @@
+package contentchef
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitForWaiters waits until n callers are waiting for the call with the given key.
+func waitForWaiters(t *testing.T, g *coalescer, key string, n int) {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		g.mu.Lock()
+		c, ok := g.calls[key]
+		waiters := 0
+		if ok {
+			waiters = c.waiters
+		}
+		g.mu.Unlock()
+		if waiters == n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("Timed out waiting for %d waiters", n)
+}
+
+func TestGet_coalescesIdenticalRequests(t *testing.T) {
+	setup()
+	defer teardown()
+	client.coalescer = newCoalescer()
+
+	var calls int32
+	release := make(chan struct{})
+	mux.HandleFunc("/foo", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		fmt.Fprint(w, `{"publicId": "foo"}`)
+	})
+
+	const callers = 10
+	var wg sync.WaitGroup
+	results := make([]*Response, callers)
+	errs := make([]error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i] = &Response{}
+			errs[i] = client.get(context.Background(), "foo", "super_secret", &ContentOptions{PublicID: "foo"}, results[i])
+		}(i)
+	}
+	waitForWaiters(t, client.coalescer, "GET "+server.URL+"/foo?publicId=foo super_secret", callers)
+	close(release)
+	wg.Wait()
+
+	if calls := atomic.LoadInt32(&calls); calls != 1 {
+		t.Errorf("Expected 1 request, got %d", calls)
+	}
+	for i := 0; i < callers; i++ {
+		if errs[i] != nil || results[i].PublicID != "foo" {
+			t.Errorf("Caller %d got %v, %v", i, results[i], errs[i])
+		}
+	}
+}
+
+func TestGet_doesNotCoalesceDifferentAPIKeys(t *testing.T) {
+	setup()
+	defer teardown()
+	client.coalescer = newCoalescer()
+
+	var calls int32
+	release := make(chan struct{})
+	mux.HandleFunc("/foo", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		fmt.Fprint(w, `{}`)
+	})
+
+	var wg sync.WaitGroup
+	for _, apiKey := range []string{"first", "second"} {
+		wg.Add(1)
+		go func(apiKey string) {
+			defer wg.Done()
+			client.get(context.Background(), "foo", apiKey, nil, &Response{})
+		}(apiKey)
+	}
+	waitForWaiters(t, client.coalescer, "GET "+server.URL+"/foo first", 1)
+	waitForWaiters(t, client.coalescer, "GET "+server.URL+"/foo second", 1)
+	close(release)
+	wg.Wait()
+
+	if calls := atomic.LoadInt32(&calls); calls != 2 {
+		t.Errorf("Expected 2 requests, got %d", calls)
+	}
+}
+
+func TestCoalescer_cancelingOneCallerDoesNotAbortTheCall(t *testing.T) {
+	g := newCoalescer()
+	release := make(chan struct{})
+	fn := func(ctx context.Context) ([]byte, error) {
+		select {
+		case <-release:
+			return []byte("data"), nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	canceled := make(chan error)
+	go func() {
+		_, err := g.do(ctx, "key", fn)
+		canceled <- err
+	}()
+	waitForWaiters(t, g, "key", 1)
+
+	result := make(chan string)
+	go func() {
+		data, _ := g.do(context.Background(), "key", fn)
+		result <- string(data)
+	}()
+	waitForWaiters(t, g, "key", 2)
+
+	cancel()
+	if err := <-canceled; err != context.Canceled {
+		t.Errorf("Canceled caller got %v, want %v", err, context.Canceled)
+	}
+	close(release)
+	if got := <-result; got != "data" {
+		t.Errorf("Other caller got %q, want data", got)
+	}
+}
+
+func TestCoalescer_cancelingEveryCallerAbortsTheCall(t *testing.T) {
+	g := newCoalescer()
+	aborted := make(chan struct{})
+	fn := func(ctx context.Context) ([]byte, error) {
+		<-ctx.Done()
+		close(aborted)
+		return nil, ctx.Err()
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		g.do(ctx, "key", fn)
+		close(done)
+	}()
+	waitForWaiters(t, g, "key", 1)
+	cancel()
+	<-done
+
+	select {
+	case <-aborted:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("Expected the shared call to be aborted")
+	}
+}
+
+func TestCoalescer_keepsContextValues(t *testing.T) {
+	type key struct{}
+	g := newCoalescer()
+	ctx := context.WithValue(context.Background(), key{}, "value")
+	data, _ := g.do(ctx, "key", func(ctx context.Context) ([]byte, error) {
+		v, _ := ctx.Value(key{}).(string)
+		return []byte(v), nil
+	})
+	if string(data) != "value" {
+		t.Errorf("Expected the context values to be kept, got %q", data)
+	}
+}
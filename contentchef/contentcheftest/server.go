@@ -0,0 +1,364 @@
+// Package contentcheftest provides a fake ContentChef API server for testing.
+//
+// The server serves the online and preview content and search endpoints out of
+// in-memory fixtures:
+//
+//	srv := contentcheftest.NewServer("mySpace")
+//	defer srv.Close()
+//	srv.AddChannel(contentcheftest.Channel{
+//		Name:          "website",
+//		OnlineAPIKey:  "onlineKey",
+//		PreviewAPIKey: "previewKey",
+//		Live:          []contentchef.Response{{PublicID: "home", Definition: "page"}},
+//	})
+//	client, _ := contentchef.NewClient(srv.ClientOptions())
+package contentcheftest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ContentChef/contentchef-go/contentchef"
+)
+
+// Channel holds the fixtures of a publishing channel.
+type Channel struct {
+	// The name of the channel
+	Name string `json:"name"`
+	// The API keys which must be sent to the online and preview endpoints
+	OnlineAPIKey  string `json:"onlineApiKey"`
+	PreviewAPIKey string `json:"previewApiKey"`
+	// The contents published in live state, served by both online and preview endpoints
+	Live []contentchef.Response `json:"live"`
+	// The contents published in staging state, served by preview endpoints only
+	Staging []contentchef.Response `json:"staging"`
+}
+
+// Fixtures is the content of a fixtures file.
+type Fixtures struct {
+	Channels []Channel `json:"channels"`
+}
+
+// Server is a fake ContentChef API server.
+//
+// Online endpoints serve live contents which are visible in the current date, while preview
+// endpoints serve the contents of the requested state, checking their visibility only when a
+// targetDate is sent.
+// Search endpoints evaluate skip, take, publicId, contentDefinition, repositories, tags,
+// sorting and propFilters with contentchef.LocalSearch.
+type Server struct {
+	*httptest.Server
+
+	// The ID of the space served
+	SpaceID string
+	// Now returns the current date, defaults to time.Now
+	Now func() time.Time
+
+	mu       sync.RWMutex
+	channels map[string]*Channel
+}
+
+// NewServer starts and returns a new Server serving the given space.
+// The caller should call Close when finished, to shut it down.
+func NewServer(spaceID string) *Server {
+	s := &Server{
+		SpaceID:  spaceID,
+		Now:      time.Now,
+		channels: make(map[string]*Channel),
+	}
+	s.Server = httptest.NewServer(s)
+	return s
+}
+
+// ClientOptions returns the options to create a contentchef.Client talking to the server.
+func (s *Server) ClientOptions() *contentchef.ClientOptions {
+	return &contentchef.ClientOptions{
+		BaseURL: s.URL + "/",
+		SpaceID: s.SpaceID,
+		Client:  s.Client(),
+	}
+}
+
+// AddChannel adds a channel to the server, replacing any channel with the same name.
+func (s *Server) AddChannel(ch Channel) error {
+	live, err := normalize(ch.Live)
+	if err != nil {
+		return err
+	}
+	staging, err := normalize(ch.Staging)
+	if err != nil {
+		return err
+	}
+	ch.Live = live
+	ch.Staging = staging
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.channels[ch.Name] = &ch
+	return nil
+}
+
+// LoadFixtures adds every channel of f to the server.
+func (s *Server) LoadFixtures(f Fixtures) error {
+	for _, ch := range f.Channels {
+		err := s.AddChannel(ch)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// LoadFile adds the channels of a JSON fixtures file to the server.
+func (s *Server) LoadFile(path string) error {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var f Fixtures
+	err = json.Unmarshal(data, &f)
+	if err != nil {
+		return fmt.Errorf("cannot decode %s: %v", path, err)
+	}
+	return s.LoadFixtures(f)
+}
+
+// normalize turns the payloads of contents into their JSON representation,
+// so that fixtures built with Go structs are evaluated like the decoded ones.
+func normalize(contents []contentchef.Response) ([]contentchef.Response, error) {
+	normalized := make([]contentchef.Response, len(contents))
+	for i, c := range contents {
+		data, err := json.Marshal(c.Payload)
+		if err != nil {
+			return nil, err
+		}
+		err = json.Unmarshal(data, &c.Payload)
+		if err != nil {
+			return nil, err
+		}
+		normalized[i] = c
+	}
+	return normalized, nil
+}
+
+// request is a parsed API request.
+type request struct {
+	preview bool
+	state   string
+	method  string
+	channel string
+}
+
+// parsePath parses paths like /space/{space}/online/{method}/{channel}
+// and /space/{space}/preview/{state}/{method}/{channel}, where method is either
+// content or search/v2.
+func parsePath(spaceID, path string) (*request, bool) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 4 || parts[0] != "space" || parts[1] != spaceID {
+		return nil, false
+	}
+	r := &request{}
+	parts = parts[2:]
+	switch parts[0] {
+	case "online":
+		parts = parts[1:]
+	case "preview":
+		r.preview = true
+		r.state = parts[1]
+		if r.state != "live" && r.state != "staging" {
+			return nil, false
+		}
+		parts = parts[2:]
+	default:
+		return nil, false
+	}
+	switch {
+	case len(parts) == 2 && parts[0] == "content":
+		r.method = "content"
+	case len(parts) == 3 && parts[0] == "search" && parts[1] == "v2":
+		r.method = "search"
+	default:
+		return nil, false
+	}
+	r.channel = parts[len(parts)-1]
+	return r, true
+}
+
+// ServeHTTP serves the ContentChef API.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+	req, ok := parsePath(s.SpaceID, r.URL.Path)
+	if !ok {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	ch, ok := s.channels[req.channel]
+	if !ok {
+		writeError(w, http.StatusNotFound, fmt.Sprintf("channel %s not found", req.channel))
+		return
+	}
+	apiKey := ch.OnlineAPIKey
+	if req.preview {
+		apiKey = ch.PreviewAPIKey
+	}
+	if r.Header.Get("X-Chef-Key") != apiKey {
+		writeError(w, http.StatusUnauthorized, "invalid api key")
+		return
+	}
+
+	q := r.URL.Query()
+	contents, err := s.visibleContents(ch, req, q.Get("targetDate"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	requestContext := contentchef.RequestContext{
+		PublishingChannel: ch.Name,
+		CloudName:         s.SpaceID,
+		Timestamp:         s.Now(),
+	}
+
+	if req.method == "content" {
+		publicID := q.Get("publicId")
+		for _, c := range contents {
+			if c.PublicID == publicID {
+				c.RequestContext = requestContext
+				writeJSON(w, http.StatusOK, c)
+				return
+			}
+		}
+		writeError(w, http.StatusNotFound, fmt.Sprintf("content %s not found", publicID))
+		return
+	}
+
+	page, err := search(contents, q)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	page.RequestContext = requestContext
+	writeJSON(w, http.StatusOK, page)
+}
+
+// visibleContents returns the contents of ch served by req.
+func (s *Server) visibleContents(ch *Channel, req *request, targetDate string) ([]contentchef.Response, error) {
+	if !req.preview {
+		return visibleAt(ch.Live, s.Now()), nil
+	}
+	contents := ch.Live
+	if req.state == "staging" {
+		contents = ch.Staging
+	}
+	if targetDate == "" {
+		return contents, nil
+	}
+	date, err := time.Parse(time.RFC3339, targetDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid targetDate %s", targetDate)
+	}
+	return visibleAt(contents, date), nil
+}
+
+// visibleAt returns the contents which are online at the given date,
+// zero online and offline dates are treated as unbounded.
+func visibleAt(contents []contentchef.Response, date time.Time) []contentchef.Response {
+	var visible []contentchef.Response
+	for _, c := range contents {
+		if !c.OnlineDate.IsZero() && date.Before(c.OnlineDate) {
+			continue
+		}
+		if !c.OfflineDate.IsZero() && !date.Before(c.OfflineDate) {
+			continue
+		}
+		visible = append(visible, c)
+	}
+	return visible
+}
+
+func search(contents []contentchef.Response, q map[string][]string) (*contentchef.PaginatedResponse, error) {
+	config := &contentchef.SearchOptions{
+		PublicID:          q["publicId"],
+		ContentDefinition: q["contentDefinition"],
+		Repositories:      q["repositories"],
+		Tags:              q["tags"],
+		Sorting:           parseSorting(first(q["sorting"])),
+	}
+	var err error
+	config.Skip, err = intParam(q, "skip")
+	if err != nil {
+		return nil, err
+	}
+	config.Take, err = intParam(q, "take")
+	if err != nil {
+		return nil, err
+	}
+	if v := first(q["propFilters"]); v != "" {
+		err := json.Unmarshal([]byte(v), &config.PropFilters)
+		if err != nil {
+			return nil, fmt.Errorf("invalid propFilters: %v", err)
+		}
+		err = config.PropFilters.Validate()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return contentchef.LocalSearch(contents, config), nil
+}
+
+// parseSorting parses the value of the sorting query parameter, eg. "+publicId,-onlineDate".
+func parseSorting(value string) contentchef.Sorting {
+	var s contentchef.Sorting
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		ascending := !strings.HasPrefix(field, "-")
+		field = strings.TrimLeft(field, "+-")
+		s = append(s, contentchef.SortingField{FieldName: field, Ascending: ascending})
+	}
+	return s
+}
+
+func first(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[0]
+}
+
+func intParam(q map[string][]string, name string) (int, error) {
+	v := first(q[name])
+	if v == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s %s", name, v)
+	}
+	return n, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, map[string]string{"message": message})
+}
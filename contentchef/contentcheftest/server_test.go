@@ -0,0 +1,258 @@
+package contentcheftest
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ContentChef/contentchef-go/contentchef"
+)
+
+type article struct {
+	Title  string   `json:"title"`
+	Author string   `json:"author"`
+	Views  int      `json:"views"`
+	Topics []string `json:"topics"`
+}
+
+func newTestServer(t *testing.T) (*Server, *contentchef.Client) {
+	now, _ := time.Parse(time.RFC3339, "2020-04-09T22:00:00Z")
+	srv := NewServer("mySpace")
+	srv.Now = func() time.Time { return now }
+	err := srv.AddChannel(Channel{
+		Name:          "blog",
+		OnlineAPIKey:  "onlineKey",
+		PreviewAPIKey: "previewKey",
+		Live: []contentchef.Response{
+			{PublicID: "go", Definition: "article", Payload: article{Title: "Learning Go", Author: "Ann", Views: 30, Topics: []string{"go", "tutorial"}}, Metadata: contentchef.Metadata{Tags: []string{"featured"}}},
+			{PublicID: "rust", Definition: "article", Payload: article{Title: "Learning Rust", Author: "Bob", Views: 10, Topics: []string{"rust"}}},
+			{PublicID: "gopher", Definition: "article", Payload: article{Title: "The Gopher", Author: "ann", Views: 20, Topics: []string{"go"}}, Metadata: contentchef.Metadata{Tags: []string{"featured"}}},
+			{PublicID: "home", Definition: "page", Payload: map[string]interface{}{"title": "Home"}},
+			{PublicID: "future", Definition: "article", Payload: article{Title: "Coming soon"}, OnlineDate: now.Add(time.Hour)},
+			{PublicID: "expired", Definition: "article", Payload: article{Title: "Gone"}, OfflineDate: now.Add(-time.Hour)},
+		},
+		Staging: []contentchef.Response{
+			{PublicID: "draft", Definition: "article", Payload: article{Title: "Draft"}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("AddChannel() returned error: %v", err)
+	}
+	client, err := contentchef.NewClient(srv.ClientOptions())
+	if err != nil {
+		t.Fatalf("NewClient() returned error: %v", err)
+	}
+	return srv, client
+}
+
+func ids(contents []contentchef.Response) []string {
+	ids := []string{}
+	for _, c := range contents {
+		ids = append(ids, c.PublicID)
+	}
+	return ids
+}
+
+func TestServer_onlineContent(t *testing.T) {
+	srv, client := newTestServer(t)
+	defer srv.Close()
+	ctx := context.TODO()
+
+	ch, _ := client.GetOnlineChannel("blog", "onlineKey")
+	got, err := ch.Content(ctx, &contentchef.ContentOptions{PublicID: "go"})
+	if err != nil {
+		t.Fatalf("Content() returned error: %v", err)
+	}
+	if got.PublicID != "go" || got.RequestContext.PublishingChannel != "blog" || got.RequestContext.CloudName != "mySpace" {
+		t.Errorf("Content() = %+v", got)
+	}
+
+	for _, publicID := range []string{"missing", "future", "expired", "draft"} {
+		_, err = ch.Content(ctx, &contentchef.ContentOptions{PublicID: publicID})
+		if !contentchef.IsNotFound(err) {
+			t.Errorf("Content(%s) error = %v, want not found", publicID, err)
+		}
+	}
+
+	wrongKey, _ := client.GetOnlineChannel("blog", "previewKey")
+	_, err = wrongKey.Content(ctx, &contentchef.ContentOptions{PublicID: "go"})
+	if !contentchef.IsUnauthorized(err) {
+		t.Errorf("Content() with wrong key error = %v, want unauthorized", err)
+	}
+
+	unknown, _ := client.GetOnlineChannel("unknown", "onlineKey")
+	_, err = unknown.Content(ctx, &contentchef.ContentOptions{PublicID: "go"})
+	if !contentchef.IsNotFound(err) {
+		t.Errorf("Content() on unknown channel error = %v, want not found", err)
+	}
+}
+
+func TestServer_previewContent(t *testing.T) {
+	srv, client := newTestServer(t)
+	defer srv.Close()
+	ctx := context.TODO()
+
+	staging, _ := client.GetPreviewChannel("blog", "previewKey", "staging")
+	if _, err := staging.Content(ctx, &contentchef.ContentOptions{PublicID: "draft"}); err != nil {
+		t.Errorf("Content(draft) returned error: %v", err)
+	}
+
+	live, _ := client.GetPreviewChannel("blog", "previewKey", "live")
+	if _, err := live.Content(ctx, &contentchef.ContentOptions{PublicID: "future"}); err != nil {
+		t.Errorf("Content(future) without target date returned error: %v", err)
+	}
+
+	client.TargetDate = srv.Now()
+	_, err := live.Content(ctx, &contentchef.ContentOptions{PublicID: "future"})
+	if !contentchef.IsNotFound(err) {
+		t.Errorf("Content(future) at current date error = %v, want not found", err)
+	}
+	client.TargetDate = srv.Now().Add(2 * time.Hour)
+	if _, err := live.Content(ctx, &contentchef.ContentOptions{PublicID: "future"}); err != nil {
+		t.Errorf("Content(future) at a future date returned error: %v", err)
+	}
+
+	onlineKey, _ := client.GetPreviewChannel("blog", "onlineKey", "live")
+	_, err = onlineKey.Content(ctx, &contentchef.ContentOptions{PublicID: "go"})
+	if !contentchef.IsUnauthorized(err) {
+		t.Errorf("Content() with online key error = %v, want unauthorized", err)
+	}
+}
+
+func TestServer_search(t *testing.T) {
+	srv, client := newTestServer(t)
+	defer srv.Close()
+
+	ch, _ := client.GetOnlineChannel("blog", "onlineKey")
+	tests := []struct {
+		name      string
+		config    *contentchef.SearchOptions
+		want      []string
+		wantTotal int
+	}{
+		{
+			name:      "every visible content",
+			config:    &contentchef.SearchOptions{},
+			want:      []string{"go", "rust", "gopher", "home"},
+			wantTotal: 4,
+		},
+		{
+			name:      "skip and take",
+			config:    &contentchef.SearchOptions{Skip: 1, Take: 2},
+			want:      []string{"rust", "gopher"},
+			wantTotal: 4,
+		},
+		{
+			name:      "skip past the end",
+			config:    &contentchef.SearchOptions{Skip: 10, Take: 2},
+			want:      []string{},
+			wantTotal: 4,
+		},
+		{
+			name:      "publicId",
+			config:    &contentchef.SearchOptions{PublicID: []string{"home", "rust"}},
+			want:      []string{"rust", "home"},
+			wantTotal: 2,
+		},
+		{
+			name:      "contentDefinition",
+			config:    &contentchef.SearchOptions{ContentDefinition: []string{"page"}},
+			want:      []string{"home"},
+			wantTotal: 1,
+		},
+		{
+			name:      "tags",
+			config:    &contentchef.SearchOptions{Tags: []string{"featured"}},
+			want:      []string{"go", "gopher"},
+			wantTotal: 2,
+		},
+		{
+			name:      "sorting",
+			config:    &contentchef.SearchOptions{ContentDefinition: []string{"article"}, Sorting: contentchef.Sorting{{FieldName: "views", Ascending: false}}},
+			want:      []string{"go", "gopher", "rust"},
+			wantTotal: 3,
+		},
+		{
+			name: "sorting on several fields",
+			config: &contentchef.SearchOptions{Sorting: contentchef.Sorting{
+				{FieldName: "definition", Ascending: false},
+				{FieldName: "publicId", Ascending: true},
+			}},
+			want:      []string{"home", "go", "gopher", "rust"},
+			wantTotal: 4,
+		},
+		{
+			name: "propFilters with AND",
+			config: &contentchef.SearchOptions{PropFilters: contentchef.PropFilters{
+				Condition: "AND",
+				Items: []contentchef.PropFilterItem{
+					{Field: "title", Operator: "CONTAINS_IC", Value: "learning"},
+					{Field: "topics", Operator: "EQUALS", Value: "go"},
+				},
+			}},
+			want:      []string{"go"},
+			wantTotal: 1,
+		},
+		{
+			name: "propFilters with OR",
+			config: &contentchef.SearchOptions{PropFilters: contentchef.PropFilters{
+				Condition: "OR",
+				Items: []contentchef.PropFilterItem{
+					{Field: "author", Operator: "EQUALS_IC", Value: "ANN"},
+					{Field: "title", Operator: "STARTS_WITH", Value: "Home"},
+				},
+			}},
+			want:      []string{"go", "gopher", "home"},
+			wantTotal: 3,
+		},
+		{
+			name: "propFilters with IN",
+			config: &contentchef.SearchOptions{PropFilters: contentchef.PropFilters{
+				Items: []contentchef.PropFilterItem{
+					{Field: "views", Operator: "IN", Value: []int{10, 20}},
+				},
+			}},
+			want:      []string{"rust", "gopher"},
+			wantTotal: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ch.Search(context.TODO(), tt.config)
+			if err != nil {
+				t.Fatalf("Search() returned error: %v", err)
+			}
+			if !reflect.DeepEqual(ids(got.Items), tt.want) || got.Total != tt.wantTotal {
+				t.Errorf("Search() = %v (total %d), want %v (total %d)", ids(got.Items), got.Total, tt.want, tt.wantTotal)
+			}
+		})
+	}
+}
+
+func TestServer_LoadFile(t *testing.T) {
+	srv := NewServer("mySpace")
+	defer srv.Close()
+	if err := srv.LoadFile("testdata/fixtures.json"); err != nil {
+		t.Fatalf("LoadFile() returned error: %v", err)
+	}
+	client, _ := contentchef.NewClient(srv.ClientOptions())
+
+	online, _ := client.GetOnlineChannel("website", "onlineKey")
+	got, err := online.Search(context.TODO(), &contentchef.SearchOptions{})
+	if err != nil {
+		t.Fatalf("Search() returned error: %v", err)
+	}
+	if want := []string{"home"}; !reflect.DeepEqual(ids(got.Items), want) {
+		t.Errorf("Search() = %v, want %v", ids(got.Items), want)
+	}
+
+	staging, _ := client.GetPreviewChannel("website", "previewKey", "staging")
+	home, err := staging.Content(context.TODO(), &contentchef.ContentOptions{PublicID: "home"})
+	if err != nil {
+		t.Fatalf("Content() returned error: %v", err)
+	}
+	if title := home.Payload.(map[string]interface{})["title"]; title != "New home" {
+		t.Errorf("Content().Payload.title = %v, want New home", title)
+	}
+}
@@ -0,0 +1,92 @@
+package contentchef
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// APIError is returned when the ContentChef API answers with a non 2xx status code.
+type APIError struct {
+	// The HTTP status code of the response
+	StatusCode int
+	// The method and the URL of the request
+	Method string
+	URL    string
+	// The error message sent by the server
+	Message string
+	// The fields of the response body, if it is a JSON object
+	Fields map[string]interface{}
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%v %v: %d %v", e.Method, e.URL, e.StatusCode, e.Message)
+}
+
+// DecodeError is returned when a response body cannot be decoded.
+type DecodeError struct {
+	// The URL of the request
+	URL string
+	// The error returned by the decoder
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("cannot decode response of %v: %v", e.URL, e.Err)
+}
+
+// Unwrap returns the error returned by the decoder.
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// IsNotFound reports whether err is an APIError with a 404 status code.
+func IsNotFound(err error) bool {
+	return hasStatus(err, func(code int) bool { return code == http.StatusNotFound })
+}
+
+// IsUnauthorized reports whether err is an APIError with a 401 status code.
+func IsUnauthorized(err error) bool {
+	return hasStatus(err, func(code int) bool { return code == http.StatusUnauthorized })
+}
+
+// IsRateLimited reports whether err is an APIError with a 429 status code.
+func IsRateLimited(err error) bool {
+	return hasStatus(err, func(code int) bool { return code == http.StatusTooManyRequests })
+}
+
+// IsServerError reports whether err is an APIError with a 5xx status code.
+func IsServerError(err error) bool {
+	return hasStatus(err, func(code int) bool { return code >= 500 && code <= 599 })
+}
+
+func hasStatus(err error, match func(int) bool) bool {
+	apiErr, ok := asAPIError(err)
+	return ok && match(apiErr.StatusCode)
+}
+
+// asAPIError looks for an APIError in err's chain of wrapped errors.
+func asAPIError(err error) (*APIError, bool) {
+	for err != nil {
+		if apiErr, ok := err.(*APIError); ok {
+			return apiErr, true
+		}
+		wrapper, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			return nil, false
+		}
+		err = wrapper.Unwrap()
+	}
+	return nil, false
+}
+
+// isUpstreamFailure reports whether err means the API could not serve a request:
+// a server error, a network error or an open circuit breaker.
+func isUpstreamFailure(err error) bool {
+	if err == ErrCircuitOpen || IsServerError(err) {
+		return true
+	}
+	// the HTTP client wraps every network error in a *url.Error
+	e, ok := err.(*url.Error)
+	return ok && e.Op != "parse"
+}
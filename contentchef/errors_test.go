@@ -0,0 +1,116 @@
+package contentchef
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+type wrappedError struct {
+	err error
+}
+
+func (e *wrappedError) Error() string { return "wrapped: " + e.err.Error() }
+func (e *wrappedError) Unwrap() error { return e.err }
+
+func TestErrorHelpers(t *testing.T) {
+	tests := []struct {
+		name             string
+		err              error
+		wantNotFound     bool
+		wantUnauthorized bool
+		wantRateLimited  bool
+		wantServerError  bool
+	}{
+		{name: "nil error", err: nil},
+		{name: "other error", err: errors.New("boom")},
+		{name: "404", err: &APIError{StatusCode: http.StatusNotFound}, wantNotFound: true},
+		{name: "401", err: &APIError{StatusCode: http.StatusUnauthorized}, wantUnauthorized: true},
+		{name: "429", err: &APIError{StatusCode: http.StatusTooManyRequests}, wantRateLimited: true},
+		{name: "500", err: &APIError{StatusCode: http.StatusInternalServerError}, wantServerError: true},
+		{name: "503", err: &APIError{StatusCode: http.StatusServiceUnavailable}, wantServerError: true},
+		{name: "wrapped 404", err: &wrappedError{&APIError{StatusCode: http.StatusNotFound}}, wantNotFound: true},
+		{name: "decode error", err: &DecodeError{Err: errors.New("boom")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsNotFound(tt.err); got != tt.wantNotFound {
+				t.Errorf("IsNotFound() = %v, want %v", got, tt.wantNotFound)
+			}
+			if got := IsUnauthorized(tt.err); got != tt.wantUnauthorized {
+				t.Errorf("IsUnauthorized() = %v, want %v", got, tt.wantUnauthorized)
+			}
+			if got := IsRateLimited(tt.err); got != tt.wantRateLimited {
+				t.Errorf("IsRateLimited() = %v, want %v", got, tt.wantRateLimited)
+			}
+			if got := IsServerError(tt.err); got != tt.wantServerError {
+				t.Errorf("IsServerError() = %v, want %v", got, tt.wantServerError)
+			}
+		})
+	}
+}
+
+func TestOnlineChannel_Content_notFound(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/space/my_space/online/content/aChannel", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"message": "content not found"}`)
+	})
+
+	ch, _ := client.GetOnlineChannel("aChannel", "superSecret")
+	_, err := ch.Content(context.TODO(), &ContentOptions{PublicID: "foo"})
+	if !IsNotFound(err) {
+		t.Fatalf("Expected a not found error, got %v", err)
+	}
+	apiErr := err.(*APIError)
+	if apiErr.Message != "content not found" || apiErr.Method != http.MethodGet {
+		t.Errorf("Unexpected APIError %#v", apiErr)
+	}
+}
+
+func TestDo_decodeError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"A":`)
+	})
+
+	req, _ := client.newRequest(http.MethodGet, "/", nil)
+	_, err := client.do(context.Background(), req, new(struct{ A string }))
+	decErr, ok := err.(*DecodeError)
+	if !ok {
+		t.Fatalf("Expected a DecodeError, got %#v", err)
+	}
+	if decErr.Unwrap() == nil || decErr.URL != server.URL+"/" {
+		t.Errorf("Unexpected DecodeError %#v", decErr)
+	}
+}
+
+func Test_isUpstreamFailure(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"server error", &APIError{StatusCode: http.StatusBadGateway}, true},
+		{"network error", &url.Error{Op: "Get", URL: "http://example.com", Err: errors.New("connection refused")}, true},
+		{"circuit open", ErrCircuitOpen, true},
+		{"client error", &APIError{StatusCode: http.StatusNotFound}, false},
+		{"parse error", &url.Error{Op: "parse", URL: "%", Err: errors.New("invalid URL escape")}, false},
+		{"canceled", context.Canceled, false},
+		{"decode error", &DecodeError{Err: errors.New("boom")}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isUpstreamFailure(tt.err); got != tt.want {
+				t.Errorf("isUpstreamFailure() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,300 @@
+package contentchef
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Match reports whether r matches the filters, evaluating them like the API does.
+//
+// Fields named publicId, definition, repository, onlineDate and offlineDate refer to the
+// content's metadata, anything else is looked up in the payload, where dots can be used
+// to walk nested objects. When a field holds an array the filter matches if any of its
+// elements does. Empty filters match every content.
+func (p PropFilters) Match(r *Response) bool {
+	if len(p.Items) == 0 {
+		return true
+	}
+	payload := normalizePayload(r.Payload)
+	or := p.Condition == ConditionOr
+	for _, item := range p.Items {
+		if item.match(r, payload) == or {
+			return or
+		}
+	}
+	return !or
+}
+
+func (p PropFilterItem) match(r *Response, payload interface{}) bool {
+	v, ok := fieldValue(r, payload, p.Field)
+	if !ok {
+		return false
+	}
+	if values, ok := v.([]interface{}); ok {
+		for _, v := range values {
+			if matchValue(v, p.Operator, p.Value) {
+				return true
+			}
+		}
+		return false
+	}
+	return matchValue(v, p.Operator, p.Value)
+}
+
+func matchValue(v interface{}, operator Operator, value interface{}) bool {
+	field := stringValue(v)
+	ignoreCase := strings.HasSuffix(string(operator), "_IC")
+	normalize := func(s string) string {
+		if ignoreCase {
+			return strings.ToLower(s)
+		}
+		return s
+	}
+	switch operator {
+	case OperatorContains, OperatorContainsIC:
+		return strings.Contains(normalize(field), normalize(stringValue(value)))
+	case OperatorEquals, OperatorEqualsIC:
+		return normalize(field) == normalize(stringValue(value))
+	case OperatorStartsWith, OperatorStartsWithIC:
+		return strings.HasPrefix(normalize(field), normalize(stringValue(value)))
+	case OperatorIn, OperatorInIC:
+		values := reflect.ValueOf(value)
+		if values.Kind() != reflect.Slice && values.Kind() != reflect.Array {
+			return false
+		}
+		for i := 0; i < values.Len(); i++ {
+			if normalize(field) == normalize(stringValue(values.Index(i).Interface())) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Sort sorts items as the API does, keeping the original order of equal items.
+//
+// Fields are looked up like in PropFilters.Match. Numbers and dates are compared by value,
+// anything else by its string representation, contents missing a field come last whatever
+// the direction.
+func (s Sorting) Sort(items []Response) {
+	if len(s) == 0 {
+		return
+	}
+	// payloads are normalized once, before sorting
+	type sortable struct {
+		content Response
+		payload interface{}
+	}
+	sorted := make([]sortable, len(items))
+	for i, item := range items {
+		sorted[i] = sortable{item, normalizePayload(item.Payload)}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := &sorted[i], &sorted[j]
+		for _, field := range s {
+			name := strings.TrimSpace(field.FieldName)
+			if name == "" {
+				continue
+			}
+			va, okA := fieldValue(&a.content, a.payload, name)
+			vb, okB := fieldValue(&b.content, b.payload, name)
+			if okA != okB {
+				return okA
+			}
+			c := compareValues(va, vb)
+			if c == 0 {
+				continue
+			}
+			if field.Ascending {
+				return c < 0
+			}
+			return c > 0
+		}
+		return false
+	})
+	for i := range sorted {
+		items[i] = sorted[i].content
+	}
+}
+
+// Match reports whether r matches every criteria of the search, evaluating them like the API does.
+//
+// PublicID, ContentDefinition and Repositories match if they contain the content's value, Tags
+// matches if the content has any of them, empty criteria match every content.
+func (o *SearchOptions) Match(r *Response) bool {
+	return oneOf(o.PublicID, r.PublicID) &&
+		oneOf(o.ContentDefinition, r.Definition) &&
+		oneOf(o.Repositories, r.Repository) &&
+		hasAnyTag(o.Tags, r.Metadata.Tags) &&
+		o.PropFilters.Match(r)
+}
+
+// LocalSearch answers a search out of a slice of contents, without calling the API.
+//
+// The contents matching config are sorted and then paginated with Skip and Take,
+// a Take lower than 1 returns every matching content and a negative Skip is treated as 0.
+func LocalSearch(contents []Response, config *SearchOptions) *PaginatedResponse {
+	if config == nil {
+		config = &SearchOptions{}
+	}
+	skip := config.Skip
+	if skip < 0 {
+		skip = 0
+	}
+	matches := []Response{}
+	for i := range contents {
+		if config.Match(&contents[i]) {
+			matches = append(matches, contents[i])
+		}
+	}
+	config.Sorting.Sort(matches)
+
+	page := &PaginatedResponse{
+		Items: []Response{},
+		Total: len(matches),
+		Skip:  skip,
+		Take:  config.Take,
+	}
+	if skip < len(matches) {
+		end := len(matches)
+		if config.Take > 0 && skip+config.Take < end {
+			end = skip + config.Take
+		}
+		page.Items = matches[skip:end]
+	}
+	return page
+}
+
+// oneOf reports whether v is one of values, an empty values slice matches everything.
+func oneOf(values []string, v string) bool {
+	if len(values) == 0 {
+		return true
+	}
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
+
+// hasAnyTag reports whether tags contains one of wanted, an empty wanted slice matches everything.
+func hasAnyTag(wanted, tags []string) bool {
+	if len(wanted) == 0 {
+		return true
+	}
+	for _, tag := range tags {
+		if oneOf(wanted, tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizePayload returns the payload as it is decoded from JSON,
+// so that payloads built with Go structs are evaluated like the decoded ones.
+func normalizePayload(payload interface{}) interface{} {
+	switch payload.(type) {
+	case nil, map[string]interface{}:
+		return payload
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return nil
+	}
+	var normalized interface{}
+	err = json.Unmarshal(data, &normalized)
+	if err != nil {
+		return nil
+	}
+	return normalized
+}
+
+// fieldValue returns the value of a content field, looking up the payload in its normalized form.
+func fieldValue(r *Response, payload interface{}, field string) (interface{}, bool) {
+	switch field {
+	case "publicId":
+		return r.PublicID, true
+	case "definition":
+		return r.Definition, true
+	case "repository":
+		return r.Repository, true
+	case "onlineDate":
+		return r.OnlineDate, true
+	case "offlineDate":
+		return r.OfflineDate, true
+	}
+	v := payload
+	for _, name := range strings.Split(field, ".") {
+		object, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		v, ok = object[name]
+		if !ok {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+func stringValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	}
+	if f, ok := floatValue(v); ok {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+	return ""
+}
+
+func floatValue(v interface{}) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+// compareValues compares numbers and dates by value and anything else by its string representation.
+func compareValues(a, b interface{}) int {
+	if fa, ok := floatValue(a); ok {
+		if fb, ok := floatValue(b); ok {
+			switch {
+			case fa < fb:
+				return -1
+			case fa > fb:
+				return 1
+			}
+			return 0
+		}
+	}
+	if ta, ok := a.(time.Time); ok {
+		if tb, ok := b.(time.Time); ok {
+			switch {
+			case ta.Before(tb):
+				return -1
+			case ta.After(tb):
+				return 1
+			}
+			return 0
+		}
+	}
+	return strings.Compare(stringValue(a), stringValue(b))
+}
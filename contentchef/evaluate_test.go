@@ -0,0 +1,135 @@
+package contentchef
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+type testPayload struct {
+	Title  string                 `json:"title"`
+	Views  int                    `json:"views"`
+	Topics []string               `json:"topics,omitempty"`
+	Author map[string]interface{} `json:"author,omitempty"`
+}
+
+func testContents() []Response {
+	date, _ := time.Parse(time.RFC3339, "2020-04-09T22:00:00Z")
+	return []Response{
+		{PublicID: "go", Definition: "article", Repository: "blog", OnlineDate: date, Payload: map[string]interface{}{"title": "Learning Go", "views": float64(30), "topics": []interface{}{"go", "tutorial"}, "author": map[string]interface{}{"name": "Ann"}}, Metadata: Metadata{Tags: []string{"featured"}}},
+		{PublicID: "rust", Definition: "article", Repository: "blog", OnlineDate: date.Add(time.Hour), Payload: testPayload{Title: "Learning Rust", Views: 10, Topics: []string{"rust"}, Author: map[string]interface{}{"name": "Bob"}}},
+		{PublicID: "gopher", Definition: "article", Repository: "blog", OnlineDate: date.Add(-time.Hour), Payload: testPayload{Title: "The Gopher", Views: 20, Topics: []string{"go"}}, Metadata: Metadata{Tags: []string{"featured", "mascot"}}},
+		{PublicID: "home", Definition: "page", Repository: "site", Payload: map[string]interface{}{"title": "Home"}},
+	}
+}
+
+func TestPropFilters_Match(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters PropFilters
+		want    []string
+	}{
+		{name: "empty filters", filters: PropFilters{}, want: []string{"go", "rust", "gopher", "home"}},
+		{name: "CONTAINS", filters: PropFilters{Items: []PropFilterItem{{Field: "title", Operator: OperatorContains, Value: "Learning"}}}, want: []string{"go", "rust"}},
+		{name: "CONTAINS is case sensitive", filters: PropFilters{Items: []PropFilterItem{{Field: "title", Operator: OperatorContains, Value: "learning"}}}, want: []string{}},
+		{name: "CONTAINS_IC", filters: PropFilters{Items: []PropFilterItem{{Field: "title", Operator: OperatorContainsIC, Value: "GOPHER"}}}, want: []string{"gopher"}},
+		{name: "EQUALS on numbers", filters: PropFilters{Items: []PropFilterItem{{Field: "views", Operator: OperatorEquals, Value: 20}}}, want: []string{"gopher"}},
+		{name: "EQUALS on arrays", filters: PropFilters{Items: []PropFilterItem{{Field: "topics", Operator: OperatorEquals, Value: "go"}}}, want: []string{"go", "gopher"}},
+		{name: "EQUALS_IC on nested fields", filters: PropFilters{Items: []PropFilterItem{{Field: "author.name", Operator: OperatorEqualsIC, Value: "bob"}}}, want: []string{"rust"}},
+		{name: "EQUALS on metadata", filters: PropFilters{Items: []PropFilterItem{{Field: "definition", Operator: OperatorEquals, Value: "page"}}}, want: []string{"home"}},
+		{name: "IN", filters: PropFilters{Items: []PropFilterItem{{Field: "views", Operator: OperatorIn, Value: []int{10, 30}}}}, want: []string{"go", "rust"}},
+		{name: "IN_IC", filters: PropFilters{Items: []PropFilterItem{{Field: "publicId", Operator: OperatorInIC, Value: []interface{}{"HOME", "Rust"}}}}, want: []string{"rust", "home"}},
+		{name: "STARTS_WITH", filters: PropFilters{Items: []PropFilterItem{{Field: "title", Operator: OperatorStartsWith, Value: "The"}}}, want: []string{"gopher"}},
+		{name: "STARTS_WITH_IC", filters: PropFilters{Items: []PropFilterItem{{Field: "title", Operator: OperatorStartsWithIC, Value: "ho"}}}, want: []string{"home"}},
+		{name: "missing fields never match", filters: PropFilters{Items: []PropFilterItem{{Field: "author.name", Operator: OperatorContains, Value: ""}}}, want: []string{"go", "rust"}},
+		{
+			name: "AND",
+			filters: PropFilters{Condition: ConditionAnd, Items: []PropFilterItem{
+				{Field: "title", Operator: OperatorContainsIC, Value: "learning"},
+				{Field: "topics", Operator: OperatorEquals, Value: "go"},
+			}},
+			want: []string{"go"},
+		},
+		{
+			name: "OR",
+			filters: PropFilters{Condition: ConditionOr, Items: []PropFilterItem{
+				{Field: "views", Operator: OperatorEquals, Value: 10},
+				{Field: "title", Operator: OperatorEquals, Value: "Home"},
+			}},
+			want: []string{"rust", "home"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := []string{}
+			contents := testContents()
+			for i := range contents {
+				if tt.filters.Match(&contents[i]) {
+					got = append(got, contents[i].PublicID)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Match() matched %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSorting_Sort(t *testing.T) {
+	tests := []struct {
+		name    string
+		sorting Sorting
+		want    []string
+	}{
+		{name: "no sorting keeps the order", sorting: nil, want: []string{"go", "rust", "gopher", "home"}},
+		{name: "numbers ascending, missing last", sorting: Sorting{{FieldName: "views", Ascending: true}}, want: []string{"rust", "gopher", "go", "home"}},
+		{name: "numbers descending, missing last", sorting: Sorting{{FieldName: "views", Ascending: false}}, want: []string{"go", "gopher", "rust", "home"}},
+		{name: "dates", sorting: Sorting{{FieldName: "onlineDate", Ascending: true}}, want: []string{"home", "gopher", "go", "rust"}},
+		{name: "strings", sorting: Sorting{{FieldName: "publicId", Ascending: true}}, want: []string{"go", "gopher", "home", "rust"}},
+		{
+			name:    "several fields",
+			sorting: Sorting{{FieldName: "repository", Ascending: false}, {FieldName: " title", Ascending: true}},
+			want:    []string{"home", "go", "rust", "gopher"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			contents := testContents()
+			tt.sorting.Sort(contents)
+			if got := publicIDs(contents); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Sort() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLocalSearch(t *testing.T) {
+	tests := []struct {
+		name      string
+		config    *SearchOptions
+		want      []string
+		wantTotal int
+	}{
+		{name: "nil options", config: nil, want: []string{"go", "rust", "gopher", "home"}, wantTotal: 4},
+		{name: "publicId", config: &SearchOptions{PublicID: []string{"home", "go"}}, want: []string{"go", "home"}, wantTotal: 2},
+		{name: "contentDefinition", config: &SearchOptions{ContentDefinition: []string{"page"}}, want: []string{"home"}, wantTotal: 1},
+		{name: "repositories", config: &SearchOptions{Repositories: []string{"blog"}}, want: []string{"go", "rust", "gopher"}, wantTotal: 3},
+		{name: "tags", config: &SearchOptions{Tags: []string{"mascot", "other"}}, want: []string{"gopher"}, wantTotal: 1},
+		{
+			name:      "sorted and paginated",
+			config:    &SearchOptions{Skip: 1, Take: 2, Sorting: Sorting{{FieldName: "publicId", Ascending: true}}},
+			want:      []string{"gopher", "home"},
+			wantTotal: 4,
+		},
+		{name: "skip past the end", config: &SearchOptions{Skip: 5, Take: 2}, want: []string{}, wantTotal: 4},
+		{name: "negative skip", config: &SearchOptions{Skip: -1, Take: 1}, want: []string{"go"}, wantTotal: 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := LocalSearch(testContents(), tt.config)
+			if ids := publicIDs(got.Items); !reflect.DeepEqual(ids, tt.want) || got.Total != tt.wantTotal {
+				t.Errorf("LocalSearch() = %v (total %d), want %v (total %d)", ids, got.Total, tt.want, tt.wantTotal)
+			}
+		})
+	}
+}
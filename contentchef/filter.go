@@ -0,0 +1,195 @@
+package contentchef
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+// Condition is the logical operator joining the items of PropFilters.
+// It is an alias of string, so that PropFilters.Condition keeps accepting plain strings.
+type Condition = string
+
+// The conditions supported by PropFilters.
+const (
+	ConditionAnd Condition = "AND"
+	ConditionOr  Condition = "OR"
+)
+
+// Operator is the operator applied by a PropFilterItem.
+// It is an alias of string, so that PropFilterItem.Operator keeps accepting plain strings.
+type Operator = string
+
+// The operators supported by PropFilterItem, the ones ending with IC ignore case.
+const (
+	OperatorContains     Operator = "CONTAINS"
+	OperatorContainsIC   Operator = "CONTAINS_IC"
+	OperatorEquals       Operator = "EQUALS"
+	OperatorEqualsIC     Operator = "EQUALS_IC"
+	OperatorIn           Operator = "IN"
+	OperatorInIC         Operator = "IN_IC"
+	OperatorStartsWith   Operator = "STARTS_WITH"
+	OperatorStartsWithIC Operator = "STARTS_WITH_IC"
+)
+
+func validOperator(o Operator) bool {
+	switch o {
+	case OperatorContains, OperatorContainsIC, OperatorEquals, OperatorEqualsIC,
+		OperatorIn, OperatorInIC, OperatorStartsWith, OperatorStartsWithIC:
+		return true
+	}
+	return false
+}
+
+// Validate checks that the condition and the operators are supported
+// and that IN operators are given a slice of values.
+func (p PropFilters) Validate() error {
+	if p.Condition != "" && p.Condition != ConditionAnd && p.Condition != ConditionOr {
+		return fmt.Errorf("propFilters: unknown condition %q", p.Condition)
+	}
+	for _, item := range p.Items {
+		err := item.Validate()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate checks that the operator is supported and that IN operators are given a slice of values.
+func (p PropFilterItem) Validate() error {
+	if p.Field == "" {
+		return errors.New("propFilters: field seems to be an empty string")
+	}
+	if !validOperator(p.Operator) {
+		return fmt.Errorf("propFilters: unknown operator %q on field %s", p.Operator, p.Field)
+	}
+	if p.Operator == OperatorIn || p.Operator == OperatorInIC {
+		kind := reflect.ValueOf(p.Value).Kind()
+		if kind != reflect.Slice && kind != reflect.Array {
+			return fmt.Errorf("propFilters: operator %s on field %s needs a slice of values, got %T", p.Operator, p.Field, p.Value)
+		}
+	}
+	return nil
+}
+
+// Where starts building a property filter on the given field.
+//
+//	filters, err := contentchef.Where("title").ContainsIC("go").
+//		And(contentchef.Where("category").In([]string{"news", "blog"})).
+//		Build()
+func Where(field string) *FieldFilter {
+	return &FieldFilter{field: field}
+}
+
+// FieldFilter is a property filter waiting for its operator.
+type FieldFilter struct {
+	field string
+}
+
+func (f *FieldFilter) is(operator Operator, value interface{}) *FilterBuilder {
+	return &FilterBuilder{
+		items: []PropFilterItem{{Field: f.field, Operator: operator, Value: value}},
+	}
+}
+
+// Contains matches contents whose field contains value.
+func (f *FieldFilter) Contains(value string) *FilterBuilder {
+	return f.is(OperatorContains, value)
+}
+
+// ContainsIC matches contents whose field contains value, ignoring case.
+func (f *FieldFilter) ContainsIC(value string) *FilterBuilder {
+	return f.is(OperatorContainsIC, value)
+}
+
+// Equals matches contents whose field is equal to value.
+func (f *FieldFilter) Equals(value interface{}) *FilterBuilder {
+	return f.is(OperatorEquals, value)
+}
+
+// EqualsIC matches contents whose field is equal to value, ignoring case.
+func (f *FieldFilter) EqualsIC(value interface{}) *FilterBuilder {
+	return f.is(OperatorEqualsIC, value)
+}
+
+// In matches contents whose field is equal to one of values, which must be a slice.
+func (f *FieldFilter) In(values interface{}) *FilterBuilder {
+	return f.is(OperatorIn, values)
+}
+
+// InIC matches contents whose field is equal to one of values, which must be a slice, ignoring case.
+func (f *FieldFilter) InIC(values interface{}) *FilterBuilder {
+	return f.is(OperatorInIC, values)
+}
+
+// StartsWith matches contents whose field starts with value.
+func (f *FieldFilter) StartsWith(value string) *FilterBuilder {
+	return f.is(OperatorStartsWith, value)
+}
+
+// StartsWithIC matches contents whose field starts with value, ignoring case.
+func (f *FieldFilter) StartsWithIC(value string) *FilterBuilder {
+	return f.is(OperatorStartsWithIC, value)
+}
+
+// FilterBuilder builds PropFilters out of property filters joined by a condition.
+//
+// The API supports a single condition, so And and Or cannot be mixed in the same builder.
+// And and Or return a new builder, so that a partial builder can be reused.
+type FilterBuilder struct {
+	condition Condition
+	items     []PropFilterItem
+	err       error
+}
+
+// And joins the builder's filters with others, matching contents which match all of them.
+func (b *FilterBuilder) And(others ...*FilterBuilder) *FilterBuilder {
+	return b.join(ConditionAnd, others)
+}
+
+// Or joins the builder's filters with others, matching contents which match any of them.
+func (b *FilterBuilder) Or(others ...*FilterBuilder) *FilterBuilder {
+	return b.join(ConditionOr, others)
+}
+
+func (b *FilterBuilder) join(condition Condition, others []*FilterBuilder) *FilterBuilder {
+	joined := &FilterBuilder{
+		condition: b.condition,
+		items:     append([]PropFilterItem(nil), b.items...),
+		err:       b.err,
+	}
+	joined.setCondition(condition)
+	for _, other := range others {
+		if other.err != nil && joined.err == nil {
+			joined.err = other.err
+		}
+		if len(other.items) > 1 {
+			joined.setCondition(other.condition)
+		}
+		joined.items = append(joined.items, other.items...)
+	}
+	return joined
+}
+
+func (b *FilterBuilder) setCondition(condition Condition) {
+	if b.condition != "" && b.condition != condition && b.err == nil {
+		b.err = errors.New("propFilters: AND and OR conditions cannot be mixed")
+	}
+	b.condition = condition
+}
+
+// Build returns the PropFilters, or an error if they are not valid.
+func (b *FilterBuilder) Build() (PropFilters, error) {
+	if b.err != nil {
+		return PropFilters{}, b.err
+	}
+	p := PropFilters{
+		Condition: b.condition,
+		Items:     b.items,
+	}
+	if p.Condition == "" {
+		p.Condition = ConditionAnd
+	}
+	return p, p.Validate()
+}
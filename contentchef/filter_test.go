@@ -0,0 +1,175 @@
+package contentchef
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestFilterBuilder_Build(t *testing.T) {
+	tests := []struct {
+		name    string
+		builder *FilterBuilder
+		want    PropFilters
+		wantErr bool
+	}{
+		{
+			name:    "a single filter defaults to AND",
+			builder: Where("title").ContainsIC("go"),
+			want: PropFilters{
+				Condition: ConditionAnd,
+				Items:     []PropFilterItem{{Field: "title", Operator: OperatorContainsIC, Value: "go"}},
+			},
+		},
+		{
+			name:    "filters joined with AND",
+			builder: Where("title").StartsWith("Go").And(Where("views").Equals(3), Where("tags").In([]string{"a", "b"})),
+			want: PropFilters{
+				Condition: ConditionAnd,
+				Items: []PropFilterItem{
+					{Field: "title", Operator: OperatorStartsWith, Value: "Go"},
+					{Field: "views", Operator: OperatorEquals, Value: 3},
+					{Field: "tags", Operator: OperatorIn, Value: []string{"a", "b"}},
+				},
+			},
+		},
+		{
+			name:    "filters joined with OR",
+			builder: Where("title").EqualsIC("go").Or(Where("author").InIC([]string{"ann", "bob"})).Or(Where("body").Contains("go")),
+			want: PropFilters{
+				Condition: ConditionOr,
+				Items: []PropFilterItem{
+					{Field: "title", Operator: OperatorEqualsIC, Value: "go"},
+					{Field: "author", Operator: OperatorInIC, Value: []string{"ann", "bob"}},
+					{Field: "body", Operator: OperatorContains, Value: "go"},
+				},
+			},
+		},
+		{
+			name:    "AND and OR cannot be mixed",
+			builder: Where("title").StartsWithIC("go").And(Where("views").Equals(3)).Or(Where("author").Equals("ann")),
+			wantErr: true,
+		},
+		{
+			name:    "nested builders with a different condition cannot be mixed",
+			builder: Where("title").Equals("go").And(Where("views").Equals(3).Or(Where("views").Equals(4))),
+			wantErr: true,
+		},
+		{
+			name:    "IN needs a slice",
+			builder: Where("views").In(3),
+			wantErr: true,
+		},
+		{
+			name:    "IN_IC needs a slice",
+			builder: Where("author").InIC("ann"),
+			wantErr: true,
+		},
+		{
+			name:    "field cannot be empty",
+			builder: Where("").Equals(3),
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.builder.Build()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Build() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Build() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterBuilder_reuse(t *testing.T) {
+	base := Where("a").Equals("1").And(Where("b").Equals("2"))
+	and := base.And(Where("c").Equals("3"))
+	other := base.And(Where("d").Equals("4"))
+
+	got, err := and.Build()
+	if err != nil {
+		t.Fatalf("Build() returned error: %v", err)
+	}
+	if len(got.Items) != 3 || got.Items[2].Field != "c" {
+		t.Errorf("Build() = %#v, want the items a, b and c", got)
+	}
+	if got, _ := other.Build(); len(got.Items) != 3 || got.Items[2].Field != "d" {
+		t.Errorf("Build() = %#v, want the items a, b and d", got)
+	}
+	if got, _ := base.Build(); len(got.Items) != 2 {
+		t.Errorf("Expected the base builder not to be modified, got %#v", got)
+	}
+
+	single := Where("a").Equals("1")
+	single.And(Where("b").Equals("2"))
+	if _, err := single.Or(Where("c").Equals("3")).Build(); err != nil {
+		t.Errorf("Expected a builder joined with And to be reusable with Or, got %v", err)
+	}
+}
+
+func TestPropFilters_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters PropFilters
+		wantErr bool
+	}{
+		{
+			name:    "empty filters are valid",
+			filters: PropFilters{},
+		},
+		{
+			name:    "condition is optional",
+			filters: PropFilters{Items: []PropFilterItem{{Field: "title", Operator: "EQUALS", Value: "go"}}},
+		},
+		{
+			name:    "IN accepts arrays",
+			filters: PropFilters{Items: []PropFilterItem{{Field: "views", Operator: OperatorIn, Value: [2]int{1, 2}}}},
+		},
+		{
+			name:    "unknown condition",
+			filters: PropFilters{Condition: "XOR", Items: []PropFilterItem{{Field: "title", Operator: "EQUALS", Value: "go"}}},
+			wantErr: true,
+		},
+		{
+			name:    "unknown operator",
+			filters: PropFilters{Items: []PropFilterItem{{Field: "title", Operator: "EQUAL", Value: "go"}}},
+			wantErr: true,
+		},
+		{
+			name:    "IN_IC with a string",
+			filters: PropFilters{Items: []PropFilterItem{{Field: "title", Operator: OperatorInIC, Value: "go"}}},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.filters.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPropFilters_plainStrings(t *testing.T) {
+	// the condition and the operators can be set from plain strings
+	condition, operator := "OR", "EQUALS"
+	p := PropFilters{Condition: condition, Items: []PropFilterItem{{Field: "title", Operator: operator, Value: "go"}}}
+	if err := p.Validate(); err != nil {
+		t.Errorf("Validate() returned error: %v", err)
+	}
+}
+
+func TestPropFilters_EncodeValuesValidates(t *testing.T) {
+	p := PropFilters{Items: []PropFilterItem{{Field: "title", Operator: OperatorIn, Value: "go"}}}
+	if err := p.EncodeValues("propFilters", &url.Values{}); err == nil {
+		t.Errorf("Expected EncodeValues() to return an error")
+	}
+
+	_, err := addOptions("/search", &SearchOptions{PropFilters: p})
+	if err == nil {
+		t.Errorf("Expected addOptions() to return an error")
+	}
+}
@@ -0,0 +1,96 @@
+package contentchef
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// The operations of a channel, as reported in RequestInfo.
+const (
+	OperationContent = "content"
+	OperationSearch  = "search"
+)
+
+// RequestInfo describes a request sent to the ContentChef API on behalf of a channel.
+type RequestInfo struct {
+	// The name of the channel
+	Channel string
+	// The operation, either OperationContent or OperationSearch
+	Operation string
+	// Whether the request is sent by a preview channel
+	Preview bool
+	// The publishing state queried by a preview channel, live or staging, empty for online channels
+	State string
+	// The attempt number, starting from 1, when the request is retried
+	Attempt int
+}
+
+// ResponseInfo describes the outcome of a request sent to the ContentChef API.
+type ResponseInfo struct {
+	RequestInfo
+	Request *http.Request
+	// The status code of the response, 0 if no response was received
+	StatusCode int
+	// The time elapsed from sending the request to closing the response body
+	Latency time.Duration
+	// The number of bytes of the response body read by the client
+	Bytes int64
+	// The error returned by the HTTP client, if no response was received
+	Err error
+}
+
+// TransportMiddleware wraps the http.RoundTripper of a Client, to observe or change
+// every request it sends. The RequestInfo of a request can be read from its context
+// with RequestInfoFromContext.
+type TransportMiddleware func(http.RoundTripper) http.RoundTripper
+
+// chainTransport wraps rt with the middlewares, the first middleware being the outermost one.
+func chainTransport(rt http.RoundTripper, middlewares []TransportMiddleware) http.RoundTripper {
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		rt = middlewares[i](rt)
+	}
+	return rt
+}
+
+type requestInfoKey struct{}
+
+// withRequestInfo returns a copy of ctx carrying info.
+// A nil ctx is returned as it is, so that the request fails as usual.
+func withRequestInfo(ctx context.Context, info RequestInfo) context.Context {
+	if ctx == nil {
+		return nil
+	}
+	return context.WithValue(ctx, requestInfoKey{}, info)
+}
+
+// RequestInfoFromContext returns the RequestInfo carried by the context of a request sent by a channel.
+func RequestInfoFromContext(ctx context.Context) (RequestInfo, bool) {
+	info, ok := ctx.Value(requestInfoKey{}).(RequestInfo)
+	return info, ok
+}
+
+// countingBody counts the bytes read from a response body and calls onClose once it is closed.
+type countingBody struct {
+	io.ReadCloser
+	n       int64
+	once    sync.Once
+	onClose func(n int64)
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.n += int64(n)
+	return n, err
+}
+
+func (b *countingBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { b.onClose(b.n) })
+	return err
+}
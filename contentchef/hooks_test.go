@@ -0,0 +1,140 @@
+package contentchef
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestClient_hooks(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/space/my_space/preview/staging/search/v2/aChannel", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Request-ID") != "abc" {
+			t.Errorf("Expected the header set by OnRequest, got %v", r.Header)
+		}
+		fmt.Fprint(w, `{"items": [], "total": 0}`)
+	})
+
+	var (
+		mu        sync.Mutex
+		requests  []RequestInfo
+		responses []ResponseInfo
+	)
+	c, err := NewClient(&ClientOptions{
+		BaseURL: server.URL + "/",
+		SpaceID: "my_space",
+		OnRequest: func(req *http.Request, info RequestInfo) {
+			mu.Lock()
+			defer mu.Unlock()
+			req.Header.Set("X-Request-ID", "abc")
+			requests = append(requests, info)
+		},
+		OnResponse: func(info ResponseInfo) {
+			mu.Lock()
+			defer mu.Unlock()
+			responses = append(responses, info)
+		},
+	})
+	if err != nil {
+		t.Fatalf("NewClient() returned error: %v", err)
+	}
+
+	ch, _ := c.GetPreviewChannel("aChannel", "superSecret", "staging")
+	_, err = ch.Search(ctx, &SearchOptions{})
+	if err != nil {
+		t.Fatalf("Search() returned error: %v", err)
+	}
+
+	want := RequestInfo{Channel: "aChannel", Operation: OperationSearch, Preview: true, State: "staging", Attempt: 1}
+	if len(requests) != 1 || requests[0] != want {
+		t.Errorf("OnRequest calls = %+v, want [%+v]", requests, want)
+	}
+	if len(responses) != 1 {
+		t.Fatalf("Expected 1 OnResponse call, got %d", len(responses))
+	}
+	res := responses[0]
+	if res.RequestInfo != want || res.StatusCode != http.StatusOK || res.Bytes != int64(len(`{"items": [], "total": 0}`)) || res.Latency <= 0 || res.Err != nil {
+		t.Errorf("OnResponse call = %+v", res)
+	}
+}
+
+func TestClient_hooksOnNetworkError(t *testing.T) {
+	var got ResponseInfo
+	c, _ := NewClient(&ClientOptions{
+		BaseURL: "http://example.com/",
+		SpaceID: "my_space",
+		Transports: []TransportMiddleware{func(http.RoundTripper) http.RoundTripper {
+			return roundTripperFunc(func(*http.Request) (*http.Response, error) {
+				return nil, errors.New("connection refused")
+			})
+		}},
+		OnResponse: func(info ResponseInfo) { got = info },
+	})
+
+	ch, _ := c.GetOnlineChannel("aChannel", "superSecret")
+	_, err := ch.Content(ctx, &ContentOptions{PublicID: "foo"})
+	if err == nil {
+		t.Fatal("Expected an error")
+	}
+	if got.Err == nil || got.StatusCode != 0 || got.Operation != OperationContent {
+		t.Errorf("OnResponse call = %+v", got)
+	}
+}
+
+func TestClient_transports(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/space/my_space/online/content/aChannel", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"publicId": %q}`, r.Header.Get("X-Order"))
+	})
+
+	var infos []RequestInfo
+	appendHeader := func(name string) TransportMiddleware {
+		return func(next http.RoundTripper) http.RoundTripper {
+			return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+				if info, ok := RequestInfoFromContext(req.Context()); ok {
+					infos = append(infos, info)
+				}
+				order := strings.TrimPrefix(req.Header.Get("X-Order")+","+name, ",")
+				req.Header.Set("X-Order", order)
+				return next.RoundTrip(req)
+			})
+		}
+	}
+	httpClient := &http.Client{}
+	c, _ := NewClient(&ClientOptions{
+		BaseURL:    server.URL + "/",
+		SpaceID:    "my_space",
+		Client:     httpClient,
+		Transports: []TransportMiddleware{appendHeader("outer"), appendHeader("inner")},
+	})
+
+	ch, _ := c.GetOnlineChannel("aChannel", "superSecret")
+	got, err := ch.Content(ctx, &ContentOptions{PublicID: "foo"})
+	if err != nil {
+		t.Fatalf("Content() returned error: %v", err)
+	}
+	if got.PublicID != "outer,inner" {
+		t.Errorf("Expected the transports to run outer first, got %q", got.PublicID)
+	}
+	want := RequestInfo{Channel: "aChannel", Operation: OperationContent, Attempt: 1}
+	if !reflect.DeepEqual(infos, []RequestInfo{want, want}) {
+		t.Errorf("RequestInfoFromContext() = %+v, want %+v", infos, want)
+	}
+	if httpClient.Transport != nil {
+		t.Errorf("Expected the HTTP client of the options to be left untouched")
+	}
+}
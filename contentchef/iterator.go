@@ -0,0 +1,114 @@
+package contentchef
+
+import (
+	"context"
+)
+
+// DefaultPageSize is the number of contents requested per page by a SearchIterator
+// when the SearchOptions do not set Take.
+const DefaultPageSize = 100
+
+// Searcher is implemented by the channels which can search for contents.
+type Searcher interface {
+	Search(ctx context.Context, config *SearchOptions) (*PaginatedResponse, error)
+}
+
+// SearchIterator walks through every content matching a search, one page at a time.
+//
+//	it := contentchef.NewSearchIterator(ctx, ch, &contentchef.SearchOptions{Take: 50})
+//	for it.Next() {
+//		content := it.Item()
+//		// ...
+//	}
+//	if err := it.Err(); err != nil {
+//		// ...
+//	}
+type SearchIterator struct {
+	ctx      context.Context
+	searcher Searcher
+	config   SearchOptions
+
+	items []Response
+	item  Response
+	done  bool
+	err   error
+}
+
+// NewSearchIterator returns a new SearchIterator reference.
+//
+// It takes a context, the channel to search and a reference to a SearchOptions struct.
+// The iteration starts from config.Skip and config.Take is used as the page size,
+// DefaultPageSize is used if it is not set.
+func NewSearchIterator(ctx context.Context, s Searcher, config *SearchOptions) *SearchIterator {
+	it := &SearchIterator{
+		ctx:      ctx,
+		searcher: s,
+	}
+	if config != nil {
+		it.config = *config
+	}
+	if it.config.Take <= 0 {
+		it.config.Take = DefaultPageSize
+	}
+	return it
+}
+
+// Next advances the iterator to the next content, fetching a new page if needed.
+// It returns false when there are no more contents or an error occurred.
+func (it *SearchIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+	if len(it.items) == 0 && !it.done {
+		it.fetch()
+	}
+	if len(it.items) == 0 {
+		return false
+	}
+	it.item = it.items[0]
+	it.items = it.items[1:]
+	return true
+}
+
+// Item returns the current content.
+func (it *SearchIterator) Item() Response {
+	return it.item
+}
+
+// Err returns the first error encountered while iterating.
+func (it *SearchIterator) Err() error {
+	return it.err
+}
+
+func (it *SearchIterator) fetch() {
+	err := it.ctx.Err()
+	if err != nil {
+		it.err = err
+		return
+	}
+	page, err := it.searcher.Search(it.ctx, &it.config)
+	if err != nil {
+		it.err = err
+		return
+	}
+	it.items = page.Items
+	it.config.Skip += len(page.Items)
+	// Total is read from every page because contents can be published or
+	// unpublished while iterating. Pages shorter than Take are not taken as
+	// the last one since the API can cap the page size.
+	if len(page.Items) == 0 || it.config.Skip >= page.Total {
+		it.done = true
+	}
+}
+
+// SearchAll returns every content matching a search, walking through all the result pages.
+//
+// It takes the same parameters of NewSearchIterator.
+func SearchAll(ctx context.Context, s Searcher, config *SearchOptions) ([]Response, error) {
+	var items []Response
+	it := NewSearchIterator(ctx, s, config)
+	for it.Next() {
+		items = append(items, it.Item())
+	}
+	return items, it.Err()
+}
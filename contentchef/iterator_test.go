@@ -0,0 +1,163 @@
+package contentchef
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// fakeSearcher serves pages out of a slice of contents, total can be changed between pages.
+type fakeSearcher struct {
+	contents []Response
+	totals   []int
+	calls    []SearchOptions
+	err      error
+}
+
+func (f *fakeSearcher) Search(ctx context.Context, config *SearchOptions) (*PaginatedResponse, error) {
+	f.calls = append(f.calls, *config)
+	if f.err != nil {
+		return nil, f.err
+	}
+	total := len(f.contents)
+	if len(f.totals) >= len(f.calls) {
+		total = f.totals[len(f.calls)-1]
+	}
+	end := config.Skip + config.Take
+	if end > total {
+		end = total
+	}
+	if end > len(f.contents) {
+		end = len(f.contents)
+	}
+	var items []Response
+	if config.Skip < end {
+		items = f.contents[config.Skip:end]
+	}
+	return &PaginatedResponse{Items: items, Total: total, Skip: config.Skip, Take: config.Take}, nil
+}
+
+func contentsWithIDs(ids ...string) []Response {
+	contents := make([]Response, len(ids))
+	for i, id := range ids {
+		contents[i] = Response{PublicID: id}
+	}
+	return contents
+}
+
+func publicIDs(contents []Response) []string {
+	ids := make([]string, len(contents))
+	for i, c := range contents {
+		ids[i] = c.PublicID
+	}
+	return ids
+}
+
+func TestSearchAll(t *testing.T) {
+	tests := []struct {
+		name      string
+		searcher  *fakeSearcher
+		config    *SearchOptions
+		want      []string
+		wantCalls int
+	}{
+		{
+			name:      "walks every page",
+			searcher:  &fakeSearcher{contents: contentsWithIDs("a", "b", "c", "d", "e")},
+			config:    &SearchOptions{Take: 2},
+			want:      []string{"a", "b", "c", "d", "e"},
+			wantCalls: 3,
+		},
+		{
+			name:      "starts from Skip",
+			searcher:  &fakeSearcher{contents: contentsWithIDs("a", "b", "c", "d", "e")},
+			config:    &SearchOptions{Skip: 3, Take: 2},
+			want:      []string{"d", "e"},
+			wantCalls: 1,
+		},
+		{
+			name:      "uses the default page size",
+			searcher:  &fakeSearcher{contents: contentsWithIDs("a", "b")},
+			config:    nil,
+			want:      []string{"a", "b"},
+			wantCalls: 1,
+		},
+		{
+			name:      "stops when total shrinks",
+			searcher:  &fakeSearcher{contents: contentsWithIDs("a", "b", "c", "d", "e"), totals: []int{5, 3}},
+			config:    &SearchOptions{Take: 2},
+			want:      []string{"a", "b", "c"},
+			wantCalls: 2,
+		},
+		{
+			name:      "follows total when it grows",
+			searcher:  &fakeSearcher{contents: contentsWithIDs("a", "b", "c", "d", "e"), totals: []int{3, 5, 5}},
+			config:    &SearchOptions{Take: 2},
+			want:      []string{"a", "b", "c", "d", "e"},
+			wantCalls: 3,
+		},
+		{
+			name:      "stops on an empty page",
+			searcher:  &fakeSearcher{contents: contentsWithIDs("a", "b"), totals: []int{4, 4}},
+			config:    &SearchOptions{Take: 2},
+			want:      []string{"a", "b"},
+			wantCalls: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SearchAll(context.TODO(), tt.searcher, tt.config)
+			if err != nil {
+				t.Fatalf("SearchAll() returned error: %v", err)
+			}
+			if ids := publicIDs(got); !reflect.DeepEqual(ids, tt.want) {
+				t.Errorf("SearchAll() = %v, want %v", ids, tt.want)
+			}
+			if len(tt.searcher.calls) != tt.wantCalls {
+				t.Errorf("SearchAll() made %d calls, want %d", len(tt.searcher.calls), tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestSearchIterator_doesNotModifyOptions(t *testing.T) {
+	config := &SearchOptions{Take: 1}
+	it := NewSearchIterator(context.TODO(), &fakeSearcher{contents: contentsWithIDs("a", "b")}, config)
+	for it.Next() {
+	}
+	if config.Skip != 0 {
+		t.Errorf("Expected the options not to be modified, got Skip %d", config.Skip)
+	}
+}
+
+func TestSearchIterator_error(t *testing.T) {
+	boom := errors.New("boom")
+	it := NewSearchIterator(context.TODO(), &fakeSearcher{err: boom}, nil)
+	if it.Next() {
+		t.Errorf("Next() = true, want false")
+	}
+	if it.Err() != boom {
+		t.Errorf("Err() = %v, want %v", it.Err(), boom)
+	}
+}
+
+func TestSearchIterator_canceledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	searcher := &fakeSearcher{contents: contentsWithIDs("a", "b", "c")}
+	it := NewSearchIterator(ctx, searcher, &SearchOptions{Take: 1})
+
+	if !it.Next() || it.Item().PublicID != "a" {
+		t.Fatalf("Expected the first content to be returned")
+	}
+	cancel()
+	if it.Next() {
+		t.Errorf("Next() = true after cancel, want false")
+	}
+	if it.Err() != context.Canceled {
+		t.Errorf("Err() = %v, want %v", it.Err(), context.Canceled)
+	}
+	if len(searcher.calls) != 1 {
+		t.Errorf("Expected 1 call, got %d", len(searcher.calls))
+	}
+}
@@ -0,0 +1,135 @@
+package contentchef
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+)
+
+// limiter caps the rate and the number of in-flight requests of a Client.
+type limiter struct {
+	bucket *tokenBucket
+	slots  chan struct{}
+}
+
+// newLimiter returns a limiter allowing rate requests per second, with bursts of burst requests,
+// and at most maxConcurrent requests in flight. A rate or maxConcurrent lower than or equal to 0
+// means no limit, and nil is returned when there are no limits at all.
+func newLimiter(rate float64, burst, maxConcurrent int) *limiter {
+	if rate <= 0 && maxConcurrent <= 0 {
+		return nil
+	}
+	l := &limiter{}
+	if rate > 0 {
+		l.bucket = newTokenBucket(rate, burst)
+	}
+	if maxConcurrent > 0 {
+		l.slots = make(chan struct{}, maxConcurrent)
+	}
+	return l
+}
+
+func noop() {}
+
+// acquire blocks until a request can be sent or ctx is done.
+// The returned function must be called once the request is over.
+func (l *limiter) acquire(ctx context.Context) (func(), error) {
+	if l == nil {
+		return noop, nil
+	}
+	if l.bucket != nil {
+		err := l.bucket.wait(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if l.slots == nil {
+		return noop, nil
+	}
+	select {
+	case l.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() { <-l.slots })
+	}, nil
+}
+
+// tokenBucket is a token bucket rate limiter.
+// Callers reserve a token in order and wait for it to be available, so they are served fairly.
+type tokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+
+	now func() time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		now:    time.Now,
+	}
+}
+
+// reserve takes a token, possibly going in debt, and returns how long to wait before using it.
+func (b *tokenBucket) reserve() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	if !b.last.IsZero() {
+		b.tokens += now.Sub(b.last).Seconds() * b.rate
+		if b.tokens > b.burst {
+			b.tokens = b.burst
+		}
+	}
+	b.last = now
+	b.tokens--
+	if b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+// cancel gives back a reserved token.
+func (b *tokenBucket) cancel() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.tokens++
+}
+
+// wait blocks until a token is available or ctx is done.
+func (b *tokenBucket) wait(ctx context.Context) error {
+	delay := b.reserve()
+	if delay == 0 {
+		return nil
+	}
+	err := sleep(ctx, delay)
+	if err != nil {
+		b.cancel()
+	}
+	return err
+}
+
+// releaseOnClose calls release when the body is closed.
+type releaseOnClose struct {
+	io.ReadCloser
+	release func()
+}
+
+func (r *releaseOnClose) Close() error {
+	err := r.ReadCloser.Close()
+	r.release()
+	return err
+}
@@ -0,0 +1,139 @@
+package contentchef
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_newLimiter(t *testing.T) {
+	if l := newLimiter(0, 0, 0); l != nil {
+		t.Errorf("Expected no limiter without limits, got %#v", l)
+	}
+	release, err := (*limiter)(nil).acquire(context.Background())
+	if err != nil {
+		t.Fatalf("acquire() on a nil limiter returned error: %v", err)
+	}
+	release()
+}
+
+func TestTokenBucket_reserve(t *testing.T) {
+	now := time.Now()
+	b := newTokenBucket(2, 3)
+	b.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		if d := b.reserve(); d != 0 {
+			t.Fatalf("reserve() %d within burst = %v, want 0", i, d)
+		}
+	}
+	if d := b.reserve(); d != 500*time.Millisecond {
+		t.Errorf("reserve() over burst = %v, want 500ms", d)
+	}
+	if d := b.reserve(); d != time.Second {
+		t.Errorf("second reserve() over burst = %v, want 1s", d)
+	}
+
+	now = now.Add(10 * time.Second)
+	for i := 0; i < 3; i++ {
+		if d := b.reserve(); d != 0 {
+			t.Fatalf("reserve() %d after refill = %v, want 0", i, d)
+		}
+	}
+	if d := b.reserve(); d == 0 {
+		t.Errorf("Expected tokens to be capped at burst")
+	}
+}
+
+func TestTokenBucket_waitHonorsContext(t *testing.T) {
+	b := newTokenBucket(0.001, 1)
+	if err := b.wait(context.Background()); err != nil {
+		t.Fatalf("wait() returned error: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := b.wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("wait() = %v, want %v", err, context.DeadlineExceeded)
+	}
+	if b.tokens < -0.01 {
+		t.Errorf("Expected the reserved token to be given back, got %v tokens", b.tokens)
+	}
+}
+
+func TestDo_rateLimit(t *testing.T) {
+	setup()
+	defer teardown()
+	client.limiter = newLimiter(50, 1, 0)
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{}`)
+	})
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		req, _ := client.newRequest(http.MethodGet, "/", nil)
+		if _, err := client.do(context.Background(), req, nil); err != nil {
+			t.Fatalf("do(): %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 55*time.Millisecond {
+		t.Errorf("4 requests at 50 per second took %v, want at least 60ms", elapsed)
+	}
+}
+
+func TestDo_maxConcurrentRequests(t *testing.T) {
+	setup()
+	defer teardown()
+	client.limiter = newLimiter(0, 0, 2)
+
+	var inFlight, maxInFlight int32
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&inFlight, 1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if n <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, n) {
+				break
+			}
+		}
+		time.Sleep(10 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		fmt.Fprint(w, `{}`)
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 6; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, _ := client.newRequest(http.MethodGet, "/", nil)
+			if _, err := client.do(context.Background(), req, nil); err != nil {
+				t.Errorf("do(): %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if max := atomic.LoadInt32(&maxInFlight); max > 2 {
+		t.Errorf("Expected at most 2 requests in flight, got %d", max)
+	}
+}
+
+func TestDo_maxConcurrentRequestsHonorsContext(t *testing.T) {
+	setup()
+	defer teardown()
+	client.limiter = newLimiter(0, 0, 1)
+
+	release, _ := client.limiter.acquire(context.Background())
+	defer release()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, _ := client.newRequest(http.MethodGet, "/", nil)
+	if _, err := client.do(ctx, req, nil); err != context.DeadlineExceeded {
+		t.Errorf("do() = %v, want %v", err, context.DeadlineExceeded)
+	}
+}
@@ -0,0 +1,179 @@
+package contentchef
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const defaultLoaderWait = 5 * time.Millisecond
+
+// LoaderOptions configures a Loader.
+type LoaderOptions struct {
+	// How long a batch collects Content calls before being sent. Defaults to 5ms
+	Wait time.Duration
+	// The maximum number of publicIds in a batch, a full batch is sent without waiting. Defaults to 100
+	MaxBatch int
+}
+
+// Loader is a Channel collecting the Content calls made within a short window into a batch,
+// which is retrieved with as few Search requests as possible, handing each content back to its caller.
+//
+// Content calls with a nil config or setting LegacyMetadata or TargetDate are not batched,
+// Search calls are passed through. A Loader can be shared, or created for every request to scope the batches to it and
+// sent with Flush once every Content call has been made.
+type Loader struct {
+	Channel
+
+	wait     time.Duration
+	maxBatch int
+
+	mu      sync.Mutex
+	pending *loaderBatch
+}
+
+type loaderBatch struct {
+	ids     []string
+	seen    map[string]bool
+	timer   *time.Timer
+	started bool
+
+	ctx     context.Context
+	cancel  context.CancelFunc
+	waiters int
+
+	done   chan struct{}
+	result *ContentManyResult
+	err    error
+}
+
+// NewLoader returns a new Loader reference batching the Content calls of ch.
+//
+// It takes the channel to wrap and a reference to a LoaderOptions struct, which can be nil.
+func NewLoader(ch Channel, o *LoaderOptions) *Loader {
+	l := &Loader{
+		Channel:  ch,
+		wait:     defaultLoaderWait,
+		maxBatch: manyChunkSize,
+	}
+	if o != nil && o.Wait > 0 {
+		l.wait = o.Wait
+	}
+	if o != nil && o.MaxBatch > 0 {
+		l.maxBatch = o.MaxBatch
+	}
+	return l
+}
+
+// WithBatching returns a Middleware wrapping channels in a Loader.
+func WithBatching(o *LoaderOptions) Middleware {
+	return func(next Channel) Channel {
+		return NewLoader(next, o)
+	}
+}
+
+// Content adds config.PublicID to the current batch and waits for it to be retrieved.
+// Contents which are not found are reported with a 404 APIError, like the API does.
+func (l *Loader) Content(ctx context.Context, config *ContentOptions) (*Response, error) {
+	if ctx == nil {
+		return nil, errors.New("context must be non-nil")
+	}
+	if config == nil || config.LegacyMetadata || !config.TargetDate.IsZero() {
+		return l.Channel.Content(ctx, config)
+	}
+
+	l.mu.Lock()
+	b := l.pending
+	if b == nil {
+		b = l.newBatch(ctx)
+	}
+	if !b.seen[config.PublicID] {
+		b.seen[config.PublicID] = true
+		b.ids = append(b.ids, config.PublicID)
+	}
+	b.waiters++
+	if len(b.ids) >= l.maxBatch {
+		// later calls go to a new batch
+		l.pending = nil
+		go l.dispatch(b)
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-b.done:
+	case <-ctx.Done():
+		l.mu.Lock()
+		b.waiters--
+		if b.waiters == 0 {
+			b.cancel()
+			// a batch nobody waits for is dropped, so that later calls do not join it
+			if !b.started {
+				b.started = true
+				b.timer.Stop()
+				if l.pending == b {
+					l.pending = nil
+				}
+			}
+		}
+		l.mu.Unlock()
+		return nil, ctx.Err()
+	}
+	if b.err != nil {
+		return nil, b.err
+	}
+	r, ok := b.result.Contents[config.PublicID]
+	if !ok {
+		return nil, &APIError{
+			StatusCode: http.StatusNotFound,
+			Method:     http.MethodGet,
+			Message:    fmt.Sprintf("content %q not found", config.PublicID),
+		}
+	}
+	return &r, nil
+}
+
+// Flush sends the current batch without waiting for the end of its window.
+func (l *Loader) Flush() {
+	l.mu.Lock()
+	b := l.pending
+	l.mu.Unlock()
+	if b != nil {
+		go l.dispatch(b)
+	}
+}
+
+// newBatch starts a new pending batch, it must be called holding the lock.
+// The batch is sent with a context carrying the values of the first caller's one,
+// which is canceled only when every caller waiting for the batch has gone.
+func (l *Loader) newBatch(ctx context.Context) *loaderBatch {
+	b := &loaderBatch{
+		seen: make(map[string]bool),
+		done: make(chan struct{}),
+	}
+	b.ctx, b.cancel = context.WithCancel(detachedContext{ctx})
+	b.timer = time.AfterFunc(l.wait, func() { l.dispatch(b) })
+	l.pending = b
+	return b
+}
+
+// dispatch sends b, unless it has already been sent.
+func (l *Loader) dispatch(b *loaderBatch) {
+	l.mu.Lock()
+	if b.started {
+		l.mu.Unlock()
+		return
+	}
+	b.started = true
+	b.timer.Stop()
+	if l.pending == b {
+		l.pending = nil
+	}
+	l.mu.Unlock()
+
+	b.result, b.err = contentMany(b.ctx, l.Channel, b.ids)
+	b.cancel()
+	close(b.done)
+}
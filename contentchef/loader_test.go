@@ -0,0 +1,171 @@
+package contentchef
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// countingChannel counts the calls to a channel and records the publicIds searched.
+type countingChannel struct {
+	Channel
+
+	mu       sync.Mutex
+	contents int
+	searches [][]string
+}
+
+func (c *countingChannel) Content(ctx context.Context, config *ContentOptions) (*Response, error) {
+	c.mu.Lock()
+	c.contents++
+	c.mu.Unlock()
+	return c.Channel.Content(ctx, config)
+}
+
+func (c *countingChannel) Search(ctx context.Context, config *SearchOptions) (*PaginatedResponse, error) {
+	c.mu.Lock()
+	c.searches = append(c.searches, append([]string(nil), config.PublicID...))
+	c.mu.Unlock()
+	return c.Channel.Search(ctx, config)
+}
+
+func newCountingChannel(ids ...string) *countingChannel {
+	return &countingChannel{Channel: NewSnapshotChannel(&Snapshot{Contents: contentsWithIDs(ids...)})}
+}
+
+// loadAll calls Content concurrently for every id and returns the publicIds loaded and the errors.
+func loadAll(ctx context.Context, l *Loader, ids ...string) ([]string, []error) {
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		loaded []string
+		errs   []error
+	)
+	for _, id := range ids {
+		wg.Add(1)
+		go func(id string) {
+			defer wg.Done()
+			r, err := l.Content(ctx, &ContentOptions{PublicID: id})
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, err)
+				return
+			}
+			loaded = append(loaded, r.PublicID)
+		}(id)
+	}
+	wg.Wait()
+	sort.Strings(loaded)
+	return loaded, errs
+}
+
+func TestLoader_batchesContentCalls(t *testing.T) {
+	ch := newCountingChannel("a", "b", "c")
+	l := NewLoader(ch, &LoaderOptions{Wait: 50 * time.Millisecond})
+
+	loaded, errs := loadAll(ctx, l, "a", "b", "c", "a", "missing")
+	if want := []string{"a", "a", "b", "c"}; !reflect.DeepEqual(loaded, want) {
+		t.Errorf("Loaded %v, want %v", loaded, want)
+	}
+	if len(errs) != 1 || !IsNotFound(errs[0]) {
+		t.Errorf("Expected a not found error for the missing content, got %v", errs)
+	}
+	if len(ch.searches) != 1 || len(ch.searches[0]) != 4 || ch.contents != 0 {
+		t.Errorf("Expected a single search for 4 ids, got searches %v and %d content calls", ch.searches, ch.contents)
+	}
+}
+
+func TestLoader_maxBatch(t *testing.T) {
+	ch := newCountingChannel("a", "b", "c")
+	l := NewLoader(ch, &LoaderOptions{Wait: time.Hour, MaxBatch: 3})
+
+	// a full batch is sent without waiting for the window
+	loaded, errs := loadAll(ctx, l, "a", "b", "c")
+	if len(loaded) != 3 || len(errs) != 0 {
+		t.Errorf("Loaded %v, errors %v", loaded, errs)
+	}
+	if len(ch.searches) != 1 {
+		t.Errorf("Expected a single search, got %v", ch.searches)
+	}
+}
+
+func TestLoader_Flush(t *testing.T) {
+	ch := newCountingChannel("a")
+	l := NewLoader(ch, &LoaderOptions{Wait: time.Hour})
+
+	done := make(chan error)
+	go func() {
+		_, err := l.Content(ctx, &ContentOptions{PublicID: "a"})
+		done <- err
+	}()
+	for {
+		l.mu.Lock()
+		pending := l.pending != nil
+		l.mu.Unlock()
+		if pending {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	l.Flush()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Content() returned error: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected Flush to send the batch")
+	}
+}
+
+func TestLoader_notBatched(t *testing.T) {
+	ch := newCountingChannel("a")
+	l := NewLoader(ch, nil)
+
+	if _, err := l.Content(ctx, &ContentOptions{PublicID: "a", LegacyMetadata: true}); err != nil {
+		t.Fatalf("Content() returned error: %v", err)
+	}
+	// a nil config reaches the wrapped channel, which reports the empty publicId as not found
+	if _, err := l.Content(ctx, nil); !IsNotFound(err) {
+		t.Fatalf("Content() with a nil config = %v, want a not found error", err)
+	}
+	if _, err := l.Search(ctx, &SearchOptions{}); err != nil {
+		t.Fatalf("Search() returned error: %v", err)
+	}
+	if ch.contents != 2 || len(ch.searches) != 1 {
+		t.Errorf("Expected the calls to be passed through, got %d content calls and searches %v", ch.contents, ch.searches)
+	}
+}
+
+func TestLoader_canceled(t *testing.T) {
+	ch := newCountingChannel("a")
+	l := NewLoader(ch, &LoaderOptions{Wait: 20 * time.Millisecond})
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := l.Content(canceled, &ContentOptions{PublicID: "a"}); err != context.Canceled {
+		t.Errorf("Content() = %v, want %v", err, context.Canceled)
+	}
+
+	// the abandoned batch is dropped, later calls are not affected
+	loaded, errs := loadAll(ctx, l, "a")
+	if len(loaded) != 1 || len(errs) != 0 {
+		t.Errorf("Loaded %v, errors %v", loaded, errs)
+	}
+	time.Sleep(40 * time.Millisecond)
+	if len(ch.searches) != 1 {
+		t.Errorf("Expected a single search, got %v", ch.searches)
+	}
+}
+
+func TestWithBatching(t *testing.T) {
+	ch := newCountingChannel("a", "b")
+	loaded, errs := loadAll(ctx, Chain(ch, WithBatching(nil)).(*Loader), "a", "b")
+	if len(loaded) != 2 || len(errs) != 0 || len(ch.searches) != 1 {
+		t.Errorf("Loaded %v, errors %v, searches %v", loaded, errs, ch.searches)
+	}
+}
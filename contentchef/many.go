@@ -0,0 +1,142 @@
+package contentchef
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"sync"
+)
+
+const (
+	// manyChunkSize is the maximum number of publicIds searched by a single request
+	manyChunkSize = DefaultPageSize
+	// manyChunkLength is the maximum length of the publicId query parameters of a single request,
+	// which keeps URLs well below the limits of servers and proxies
+	manyChunkLength = 1500
+	// manyConcurrency is the maximum number of requests in flight for a single ContentMany call
+	manyConcurrency = 4
+)
+
+// ContentManyResult holds the contents retrieved by ContentMany.
+type ContentManyResult struct {
+	// The contents found, keyed by publicId
+	Contents map[string]Response
+	// The publicIds which were not found, in the order they were requested
+	Missing []string
+}
+
+// ContentMany returns the contents with the given publicIds.
+//
+// The publicIds are searched in chunks which keep the request URLs short, running a few chunks
+// concurrently. Duplicated and empty publicIds are ignored, the ones not found are reported
+// in Missing. The first error stops the remaining chunks and is returned.
+func (s *OnlineChannel) ContentMany(ctx context.Context, ids []string) (*ContentManyResult, error) {
+	return contentMany(ctx, s, ids)
+}
+
+// ContentMany returns the contents with the given publicIds.
+//
+// It works like OnlineChannel.ContentMany.
+func (s *PreviewChannel) ContentMany(ctx context.Context, ids []string) (*ContentManyResult, error) {
+	return contentMany(ctx, s, ids)
+}
+
+func contentMany(ctx context.Context, s Searcher, ids []string) (*ContentManyResult, error) {
+	if ctx == nil {
+		return nil, errors.New("context must be non-nil")
+	}
+	ids = uniqueIDs(ids)
+	chunks := chunkIDs(ids, manyChunkSize, manyChunkLength)
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		contents = make(map[string]Response, len(ids))
+		slots    = make(chan struct{}, manyConcurrency)
+	)
+	for _, chunk := range chunks {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(chunk []string) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			items, err := SearchAll(ctx, s, &SearchOptions{PublicID: chunk, Take: len(chunk)})
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+					cancel()
+				}
+				return
+			}
+			for _, item := range items {
+				contents[item.PublicID] = item
+			}
+		}(chunk)
+	}
+	wg.Wait()
+
+	if firstErr == nil {
+		firstErr = ctx.Err()
+	}
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	result := &ContentManyResult{Contents: contents}
+	for _, id := range ids {
+		if _, ok := contents[id]; !ok {
+			result.Missing = append(result.Missing, id)
+		}
+	}
+	return result, nil
+}
+
+// uniqueIDs returns ids without duplicates and empty strings, keeping their order.
+func uniqueIDs(ids []string) []string {
+	seen := make(map[string]bool, len(ids))
+	unique := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+	return unique
+}
+
+// chunkIDs splits ids in chunks of at most maxIDs publicIds, whose publicId
+// query parameters are at most maxLength bytes long. An id longer than
+// maxLength gets a chunk of its own.
+func chunkIDs(ids []string, maxIDs, maxLength int) [][]string {
+	var (
+		chunks [][]string
+		chunk  []string
+		length int
+	)
+	for _, id := range ids {
+		n := len("publicId=&") + len(url.QueryEscape(id))
+		if len(chunk) > 0 && (len(chunk) == maxIDs || length+n > maxLength) {
+			chunks = append(chunks, chunk)
+			chunk, length = nil, 0
+		}
+		chunk = append(chunk, id)
+		length += n
+	}
+	if len(chunk) > 0 {
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
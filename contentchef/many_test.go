@@ -0,0 +1,119 @@
+package contentchef
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func Test_chunkIDs(t *testing.T) {
+	tests := []struct {
+		name      string
+		ids       []string
+		maxIDs    int
+		maxLength int
+		want      [][]string
+	}{
+		{name: "no ids", ids: nil, maxIDs: 2, maxLength: 100, want: nil},
+		{name: "by count", ids: []string{"a", "b", "c"}, maxIDs: 2, maxLength: 100, want: [][]string{{"a", "b"}, {"c"}}},
+		// every "publicId=x&" is 11 bytes long
+		{name: "by length", ids: []string{"a", "b", "c"}, maxIDs: 10, maxLength: 22, want: [][]string{{"a", "b"}, {"c"}}},
+		{name: "escaped length", ids: []string{"a b", "c"}, maxIDs: 10, maxLength: 23, want: [][]string{{"a b"}, {"c"}}},
+		{name: "long id", ids: []string{"a", strings.Repeat("x", 50), "b"}, maxIDs: 10, maxLength: 22, want: [][]string{{"a"}, {strings.Repeat("x", 50)}, {"b"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := chunkIDs(tt.ids, tt.maxIDs, tt.maxLength); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("chunkIDs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOnlineChannel_ContentMany(t *testing.T) {
+	setup()
+	defer teardown()
+
+	var (
+		mu       sync.Mutex
+		requests int
+	)
+	snapshot := NewSnapshotChannel(&Snapshot{Contents: contentsWithIDs(manyIDs(250)...)})
+	mux.HandleFunc("/space/my_space/online/search/v2/aChannel", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		ids := r.URL.Query()["publicId"]
+		if len(ids) > manyChunkSize {
+			t.Errorf("Expected at most %d ids per request, got %d", manyChunkSize, len(ids))
+		}
+		page, _ := snapshot.Search(r.Context(), &SearchOptions{PublicID: ids})
+		fmt.Fprintf(w, `{"items": [`)
+		for i, item := range page.Items {
+			if i > 0 {
+				fmt.Fprint(w, ",")
+			}
+			fmt.Fprintf(w, `{"publicId": %q}`, item.PublicID)
+		}
+		fmt.Fprintf(w, `], "total": %d}`, page.Total)
+	})
+
+	ids := append(manyIDs(300), "id-0", "")
+	ch, _ := client.GetOnlineChannel("aChannel", "superSecret")
+	got, err := ch.ContentMany(ctx, ids)
+	if err != nil {
+		t.Fatalf("ContentMany() returned error: %v", err)
+	}
+	if len(got.Contents) != 250 || got.Contents["id-249"].PublicID != "id-249" {
+		t.Errorf("ContentMany() returned %d contents, want 250", len(got.Contents))
+	}
+	if want := manyIDs(300)[250:]; !reflect.DeepEqual(got.Missing, want) {
+		t.Errorf("ContentMany().Missing = %v, want %v", got.Missing, want)
+	}
+	// the query of an id-N is up to 16 bytes long, so the length limit splits the ids in 4 chunks
+	if requests != 4 {
+		t.Errorf("Expected 4 requests, got %d", requests)
+	}
+}
+
+func TestPreviewChannel_ContentManyError(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/space/my_space/preview/live/search/v2/aChannel", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message": "unauthorized"}`, http.StatusUnauthorized)
+	})
+
+	ch, _ := client.GetPreviewChannel("aChannel", "superSecret", "live")
+	_, err := ch.ContentMany(ctx, manyIDs(500))
+	if !IsUnauthorized(err) {
+		t.Errorf("Expected an unauthorized error, got %v", err)
+	}
+
+	_, err = ch.ContentMany(nil, manyIDs(1))
+	if err == nil {
+		t.Errorf("Expected a nil context to return an error")
+	}
+}
+
+func Test_contentManyCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := contentMany(ctx, &fakeSearcher{err: errors.New("boom")}, manyIDs(10))
+	if err != context.Canceled {
+		t.Errorf("contentMany() = %v, want %v", err, context.Canceled)
+	}
+}
+
+func manyIDs(n int) []string {
+	ids := make([]string, n)
+	for i := range ids {
+		ids[i] = fmt.Sprintf("id-%d", i)
+	}
+	return ids
+}
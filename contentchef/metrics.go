@@ -0,0 +1,221 @@
+package contentchef
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultLatencyBuckets are the upper bounds, in seconds, of the request latency histogram.
+var DefaultLatencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Metrics collects the metrics of the requests sent by one or more clients,
+// and serves them in the Prometheus text exposition format.
+//
+// Every metric is labeled with the channel, the operation, whether the channel is a preview one
+// and the publishing state it queries, live or staging, empty for online channels:
+//
+//	contentchef_requests_total                 requests sent to the API, retries included
+//	contentchef_request_errors_total           requests failed, also labeled with the class: 4xx, 5xx or network
+//	contentchef_request_duration_seconds       histogram of the request latency
+//	contentchef_response_bytes_total           bytes of the response bodies read
+//	contentchef_cache_hits_total               calls answered by the client's Cache
+//	contentchef_cache_misses_total             calls not found in the client's Cache
+//	contentchef_stale_responses_total          calls answered with a stale response
+type Metrics struct {
+	buckets []float64
+
+	mu     sync.Mutex
+	series map[metricLabels]*metricSeries
+	errors map[errorLabels]int64
+}
+
+type metricLabels struct {
+	channel   string
+	operation string
+	preview   bool
+	state     string
+}
+
+type errorLabels struct {
+	metricLabels
+	class string
+}
+
+type metricSeries struct {
+	requests    int64
+	bytes       int64
+	cacheHits   int64
+	cacheMisses int64
+	stale       int64
+	buckets     []int64
+	sum         float64
+}
+
+// NewMetrics returns a new Metrics reference, using DefaultLatencyBuckets for the latency histogram.
+func NewMetrics() *Metrics {
+	return &Metrics{
+		buckets: DefaultLatencyBuckets,
+		series:  make(map[metricLabels]*metricSeries),
+		errors:  make(map[errorLabels]int64),
+	}
+}
+
+func labelsOf(info RequestInfo) metricLabels {
+	return metricLabels{channel: info.Channel, operation: info.Operation, preview: info.Preview, state: info.State}
+}
+
+// get returns the series of labels, creating it if needed. It must be called holding the lock.
+func (m *Metrics) get(labels metricLabels) *metricSeries {
+	s, ok := m.series[labels]
+	if !ok {
+		s = &metricSeries{buckets: make([]int64, len(m.buckets))}
+		m.series[labels] = s
+	}
+	return s
+}
+
+func (m *Metrics) observeResponse(info ResponseInfo) {
+	labels := labelsOf(info.RequestInfo)
+	seconds := info.Latency.Seconds()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.get(labels)
+	s.requests++
+	s.bytes += info.Bytes
+	s.sum += seconds
+	for i, bound := range m.buckets {
+		if seconds <= bound {
+			s.buckets[i]++
+		}
+	}
+	if class := errorClass(info); class != "" {
+		m.errors[errorLabels{labels, class}]++
+	}
+}
+
+func errorClass(info ResponseInfo) string {
+	switch {
+	case info.Err != nil:
+		return "network"
+	case info.StatusCode >= 500:
+		return "5xx"
+	case info.StatusCode >= 400:
+		return "4xx"
+	}
+	return ""
+}
+
+func (m *Metrics) observeCache(ctx context.Context, hit bool) {
+	if m == nil || ctx == nil {
+		return
+	}
+	info, _ := RequestInfoFromContext(ctx)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	s := m.get(labelsOf(info))
+	if hit {
+		s.cacheHits++
+	} else {
+		s.cacheMisses++
+	}
+}
+
+func (m *Metrics) observeStale(ctx context.Context) {
+	if m == nil || ctx == nil {
+		return
+	}
+	info, _ := RequestInfoFromContext(ctx)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.get(labelsOf(info)).stale++
+}
+
+// ServeHTTP writes the metrics in the Prometheus text exposition format.
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.write(w)
+}
+
+func (m *Metrics) write(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	labels := make([]metricLabels, 0, len(m.series))
+	for l := range m.series {
+		labels = append(labels, l)
+	}
+	sort.Slice(labels, func(i, j int) bool { return labels[i].String() < labels[j].String() })
+	errs := make([]errorLabels, 0, len(m.errors))
+	for l := range m.errors {
+		errs = append(errs, l)
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].String() < errs[j].String() })
+
+	b := bufio.NewWriter(w)
+	counter := func(name, help string, value func(*metricSeries) int64) {
+		fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s counter\n", name, help, name)
+		for _, l := range labels {
+			fmt.Fprintf(b, "%s{%s} %d\n", name, l, value(m.series[l]))
+		}
+	}
+
+	counter("contentchef_requests_total", "Requests sent to the ContentChef API.",
+		func(s *metricSeries) int64 { return s.requests })
+
+	fmt.Fprint(b, "# HELP contentchef_request_errors_total Requests to the ContentChef API which failed, by class.\n")
+	fmt.Fprint(b, "# TYPE contentchef_request_errors_total counter\n")
+	for _, l := range errs {
+		fmt.Fprintf(b, "contentchef_request_errors_total{%s} %d\n", l, m.errors[l])
+	}
+
+	fmt.Fprint(b, "# HELP contentchef_request_duration_seconds Latency of the requests to the ContentChef API.\n")
+	fmt.Fprint(b, "# TYPE contentchef_request_duration_seconds histogram\n")
+	for _, l := range labels {
+		s := m.series[l]
+		for i, bound := range m.buckets {
+			fmt.Fprintf(b, "contentchef_request_duration_seconds_bucket{%s,le=\"%s\"} %d\n", l, formatFloat(bound), s.buckets[i])
+		}
+		fmt.Fprintf(b, "contentchef_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", l, s.requests)
+		fmt.Fprintf(b, "contentchef_request_duration_seconds_sum{%s} %s\n", l, formatFloat(s.sum))
+		fmt.Fprintf(b, "contentchef_request_duration_seconds_count{%s} %d\n", l, s.requests)
+	}
+
+	counter("contentchef_response_bytes_total", "Bytes of the ContentChef API responses read.",
+		func(s *metricSeries) int64 { return s.bytes })
+	counter("contentchef_cache_hits_total", "Channel calls answered by the client cache.",
+		func(s *metricSeries) int64 { return s.cacheHits })
+	counter("contentchef_cache_misses_total", "Channel calls not found in the client cache.",
+		func(s *metricSeries) int64 { return s.cacheMisses })
+	counter("contentchef_stale_responses_total", "Channel calls answered with a stale response.",
+		func(s *metricSeries) int64 { return s.stale })
+
+	return b.Flush()
+}
+
+func (l metricLabels) String() string {
+	return fmt.Sprintf(`channel="%s",operation="%s",preview="%t",state="%s"`, escapeLabel(l.channel), escapeLabel(l.operation), l.preview, escapeLabel(l.state))
+}
+
+func (l errorLabels) String() string {
+	return fmt.Sprintf(`%s,class="%s"`, l.metricLabels, l.class)
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(v string) string {
+	return labelEscaper.Replace(v)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,102 @@
+package contentchef
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	setup()
+	defer teardown()
+	metrics := NewMetrics()
+	c, _ := NewClient(&ClientOptions{
+		BaseURL: server.URL + "/",
+		SpaceID: "my_space",
+		Cache:   NewLRUCache(10, time.Minute),
+		Metrics: metrics,
+	})
+
+	mux.HandleFunc("/space/my_space/online/content/aChannel", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"publicId": "foo"}`)
+	})
+	mux.HandleFunc("/space/my_space/preview/live/search/v2/aChannel", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	})
+
+	online, _ := c.GetOnlineChannel("aChannel", "superSecret")
+	for i := 0; i < 2; i++ {
+		if _, err := online.Content(ctx, &ContentOptions{PublicID: "foo"}); err != nil {
+			t.Fatalf("Content() returned error: %v", err)
+		}
+	}
+	preview, _ := c.GetPreviewChannel("aChannel", "superSecret", "live")
+	preview.Search(ctx, &SearchOptions{})
+	staging, _ := c.GetPreviewChannel("aChannel", "superSecret", "staging")
+	staging.Search(ctx, &SearchOptions{})
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	body := rec.Body.String()
+
+	online0 := `channel="aChannel",operation="content",preview="false",state=""`
+	preview0 := `channel="aChannel",operation="search",preview="true",state="live"`
+	staging0 := `channel="aChannel",operation="search",preview="true",state="staging"`
+	for _, want := range []string{
+		"# TYPE contentchef_requests_total counter\n",
+		"contentchef_requests_total{" + online0 + "} 1\n",
+		"contentchef_requests_total{" + preview0 + "} 1\n",
+		"contentchef_request_errors_total{" + preview0 + `,class="5xx"} 1` + "\n",
+		"# TYPE contentchef_request_duration_seconds histogram\n",
+		"contentchef_request_duration_seconds_bucket{" + online0 + `,le="10"} 1` + "\n",
+		"contentchef_request_duration_seconds_bucket{" + online0 + `,le="+Inf"} 1` + "\n",
+		"contentchef_request_duration_seconds_count{" + online0 + "} 1\n",
+		"contentchef_response_bytes_total{" + online0 + "} 19\n",
+		"contentchef_cache_hits_total{" + online0 + "} 1\n",
+		"contentchef_cache_misses_total{" + online0 + "} 1\n",
+		"contentchef_cache_misses_total{" + preview0 + "} 1\n",
+		// staging requests get series of their own
+		"contentchef_requests_total{" + staging0 + "} 1\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("Expected the metrics to contain %q, got:\n%s", want, body)
+		}
+	}
+	if strings.Contains(body, online0+`,class=`) {
+		t.Errorf("Expected no errors for the online channel, got:\n%s", body)
+	}
+}
+
+func TestMetrics_histogram(t *testing.T) {
+	m := NewMetrics()
+	info := RequestInfo{Channel: `a"b`, Operation: OperationSearch}
+	for _, latency := range []time.Duration{time.Millisecond, 200 * time.Millisecond, time.Minute} {
+		m.observeResponse(ResponseInfo{RequestInfo: info, StatusCode: http.StatusOK, Latency: latency})
+	}
+	m.observeResponse(ResponseInfo{RequestInfo: info, Err: fmt.Errorf("connection refused")})
+
+	var buf bytes.Buffer
+	if err := m.write(&buf); err != nil {
+		t.Fatalf("write() returned error: %v", err)
+	}
+	labels := `channel="a\"b",operation="search",preview="false",state=""`
+	for _, want := range []string{
+		"contentchef_request_duration_seconds_bucket{" + labels + `,le="0.005"} 2`,
+		"contentchef_request_duration_seconds_bucket{" + labels + `,le="0.25"} 3`,
+		"contentchef_request_duration_seconds_bucket{" + labels + `,le="10"} 3`,
+		"contentchef_request_duration_seconds_bucket{" + labels + `,le="+Inf"} 4`,
+		"contentchef_request_duration_seconds_sum{" + labels + "} 60.201",
+		"contentchef_request_errors_total{" + labels + `,class="network"} 1`,
+	} {
+		if !strings.Contains(buf.String(), want+"\n") {
+			t.Errorf("Expected the metrics to contain %q, got:\n%s", want, buf.String())
+		}
+	}
+}
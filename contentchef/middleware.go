@@ -0,0 +1,61 @@
+package contentchef
+
+import (
+	"context"
+)
+
+// Channel is implemented by every channel: OnlineChannel, PreviewChannel, SnapshotChannel
+// and the channels returned by a Middleware.
+type Channel interface {
+	Content(ctx context.Context, config *ContentOptions) (*Response, error)
+	Search(ctx context.Context, config *SearchOptions) (*PaginatedResponse, error)
+}
+
+var (
+	_ Channel = (*OnlineChannel)(nil)
+	_ Channel = (*PreviewChannel)(nil)
+	_ Channel = (*SnapshotChannel)(nil)
+)
+
+// Middleware wraps a Channel to add behavior around its methods, like logging or caching.
+//
+// A middleware usually returns a struct embedding the wrapped channel and overriding
+// the methods it is interested in.
+type Middleware func(Channel) Channel
+
+// Chain wraps ch with the middlewares, the first middleware being the outermost one.
+func Chain(ch Channel, middlewares ...Middleware) Channel {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		ch = middlewares[i](ch)
+	}
+	return ch
+}
+
+// WithFallback returns a Middleware answering from fallback, like a SnapshotChannel,
+// when the wrapped channel fails with a server error, a network error or an open circuit breaker.
+func WithFallback(fallback Channel) Middleware {
+	return func(next Channel) Channel {
+		return &fallbackChannel{Channel: next, fallback: fallback}
+	}
+}
+
+type fallbackChannel struct {
+	Channel
+	fallback Channel
+}
+
+func (c *fallbackChannel) Content(ctx context.Context, config *ContentOptions) (*Response, error) {
+	r, err := c.Channel.Content(ctx, config)
+	if err != nil && isUpstreamFailure(err) {
+		return c.fallback.Content(ctx, config)
+	}
+	return r, err
+}
+
+func (c *fallbackChannel) Search(ctx context.Context, config *SearchOptions) (*PaginatedResponse, error) {
+	r, err := c.Channel.Search(ctx, config)
+	if err != nil && isUpstreamFailure(err) {
+		return c.fallback.Search(ctx, config)
+	}
+	return r, err
+}
@@ -0,0 +1,90 @@
+package contentchef
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// tracingChannel records the calls going through it.
+type tracingChannel struct {
+	Channel
+	name  string
+	calls *[]string
+}
+
+func (c *tracingChannel) Content(ctx context.Context, config *ContentOptions) (*Response, error) {
+	*c.calls = append(*c.calls, c.name)
+	return c.Channel.Content(ctx, config)
+}
+
+func tracing(name string, calls *[]string) Middleware {
+	return func(next Channel) Channel {
+		return &tracingChannel{Channel: next, name: name, calls: calls}
+	}
+}
+
+func TestChain(t *testing.T) {
+	var calls []string
+	ch := Chain(NewSnapshotChannel(&Snapshot{Contents: contentsWithIDs("a")}), tracing("outer", &calls), tracing("inner", &calls))
+
+	got, err := ch.Content(ctx, &ContentOptions{PublicID: "a"})
+	if err != nil {
+		t.Fatalf("Content() returned error: %v", err)
+	}
+	if got.PublicID != "a" {
+		t.Errorf("Content().PublicID = %v, want a", got.PublicID)
+	}
+	if want := []string{"outer", "inner"}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("Middleware calls = %v, want %v", calls, want)
+	}
+
+	// methods not overridden by a middleware reach the wrapped channel
+	page, err := ch.Search(ctx, nil)
+	if err != nil || page.Total != 1 {
+		t.Errorf("Search() = %+v, %v", page, err)
+	}
+}
+
+func TestWithFallback(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/space/my_space/online/content/aChannel", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("publicId") == "missing" {
+			http.Error(w, `{"message":"not found"}`, http.StatusNotFound)
+			return
+		}
+		http.Error(w, `{"message":"unavailable"}`, http.StatusServiceUnavailable)
+	})
+	mux.HandleFunc("/space/my_space/online/search/v2/aChannel", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"items": [{"publicId": "live"}], "total": 1}`)
+	})
+
+	online, _ := client.GetOnlineChannel("aChannel", "superSecret")
+	ch := Chain(online, WithFallback(NewSnapshotChannel(&Snapshot{Contents: contentsWithIDs("a", "missing")})))
+
+	got, err := ch.Content(ctx, &ContentOptions{PublicID: "a"})
+	if err != nil {
+		t.Fatalf("Content() returned error: %v", err)
+	}
+	if got.PublicID != "a" {
+		t.Errorf("Expected the fallback content, got %+v", got)
+	}
+
+	// client errors are not hidden by the fallback
+	_, err = ch.Content(ctx, &ContentOptions{PublicID: "missing"})
+	if !IsNotFound(err) {
+		t.Errorf("Expected a not found error, got %v", err)
+	}
+
+	page, err := ch.Search(ctx, &SearchOptions{})
+	if err != nil {
+		t.Fatalf("Search() returned error: %v", err)
+	}
+	if got := publicIDs(page.Items); !reflect.DeepEqual(got, []string{"live"}) {
+		t.Errorf("Expected the live contents, got %v", got)
+	}
+}
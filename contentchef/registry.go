@@ -0,0 +1,260 @@
+package contentchef
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// UnknownDefinitionError is returned when a content's definition has no type registered
+// and the registry has no fallback type.
+type UnknownDefinitionError struct {
+	// The publicId of the content
+	PublicID string
+	// The definition of the content
+	Definition string
+}
+
+func (e *UnknownDefinitionError) Error() string {
+	return fmt.Sprintf("no type registered for definition %q of content %q", e.Definition, e.PublicID)
+}
+
+// Registry maps content definitions to the Go types their payloads are decoded into,
+// so that search results mixing several definitions come back as typed values.
+//
+//	registry := contentchef.NewRegistry()
+//	registry.Register("article", Article{})
+//	registry.Register("video", Video{})
+//
+//	page, err := registry.Search(ctx, ch, &contentchef.SearchOptions{Take: 10})
+//	for _, item := range page.Items {
+//		switch payload := item.Payload.(type) {
+//		case *Article:
+//			// ...
+//		case *Video:
+//			// ...
+//		}
+//	}
+//
+// A Registry is safe for concurrent use.
+type Registry struct {
+	mu       sync.RWMutex
+	types    map[string]reflect.Type
+	fallback reflect.Type
+}
+
+// NewRegistry returns a new empty Registry reference.
+func NewRegistry() *Registry {
+	return &Registry{types: make(map[string]reflect.Type)}
+}
+
+// Register sets the type of the payloads of definition to the type of v.
+// v can be a value or a pointer, payloads are always decoded into a pointer to a new value.
+//
+// It returns an error if definition is empty, v is nil or definition is already registered.
+func (r *Registry) Register(definition string, v interface{}) error {
+	if definition == "" {
+		return errors.New("definition must be non-empty")
+	}
+	t, err := payloadType(v)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.types[definition]; ok {
+		return fmt.Errorf("definition %q is already registered", definition)
+	}
+	r.types[definition] = t
+	return nil
+}
+
+// RegisterFallback sets the type of the payloads of the definitions which are not registered
+// to the type of v. Registering a map[string]interface{} keeps their payloads generic.
+//
+// Without a fallback, decoding a content of an unknown definition returns an UnknownDefinitionError.
+func (r *Registry) RegisterFallback(v interface{}) error {
+	t, err := payloadType(v)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	r.fallback = t
+	r.mu.Unlock()
+	return nil
+}
+
+func payloadType(v interface{}) (reflect.Type, error) {
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return nil, errors.New("type must be non-nil")
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t, nil
+}
+
+// rawChannel is implemented by the channels able to keep payloads undecoded,
+// which the registry decodes once, straight into the registered types.
+type rawChannel interface {
+	ContentRaw(ctx context.Context, config *ContentOptions) (*RawResponse, error)
+	SearchRaw(ctx context.Context, config *SearchOptions) (*RawPaginatedResponse, error)
+}
+
+var (
+	_ rawChannel = (*OnlineChannel)(nil)
+	_ rawChannel = (*PreviewChannel)(nil)
+)
+
+// Decode returns a copy of content whose payload is decoded into a pointer to the type
+// registered for its definition.
+//
+// The payload of content has already been decoded once, DecodeRaw avoids decoding it twice.
+func (r *Registry) Decode(content *Response) (*Response, error) {
+	data, err := json.Marshal(content.Payload)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := r.decodePayload(content.PublicID, content.Definition, data)
+	if err != nil {
+		return nil, err
+	}
+	decoded := *content
+	decoded.Payload = payload
+	return &decoded, nil
+}
+
+// DecodeRaw returns content as a Response whose payload is decoded into a pointer to the type
+// registered for its definition.
+func (r *Registry) DecodeRaw(content *RawResponse) (*Response, error) {
+	payload, err := r.decodePayload(content.PublicID, content.Definition, content.Payload)
+	if err != nil {
+		return nil, err
+	}
+	return &Response{
+		PublicID:       content.PublicID,
+		Definition:     content.Definition,
+		Repository:     content.Repository,
+		Payload:        payload,
+		OnlineDate:     content.OnlineDate,
+		OfflineDate:    content.OfflineDate,
+		Metadata:       content.Metadata,
+		RequestContext: content.RequestContext,
+		Stale:          content.Stale,
+	}, nil
+}
+
+// decodePayload decodes data into a pointer to a new value of the type registered for definition.
+func (r *Registry) decodePayload(publicID, definition string, data []byte) (interface{}, error) {
+	r.mu.RLock()
+	t, ok := r.types[definition]
+	if !ok {
+		t = r.fallback
+	}
+	r.mu.RUnlock()
+	if t == nil {
+		return nil, &UnknownDefinitionError{PublicID: publicID, Definition: definition}
+	}
+
+	v := reflect.New(t).Interface()
+	err := json.Unmarshal(data, v)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode payload of content %q into %v: %v", publicID, t, err)
+	}
+	return v, nil
+}
+
+// DecodePage returns a copy of page whose items' payloads are decoded like Decode does.
+// The first item which cannot be decoded stops the decoding and its error is returned.
+func (r *Registry) DecodePage(page *PaginatedResponse) (*PaginatedResponse, error) {
+	decoded := *page
+	decoded.Items = make([]Response, len(page.Items))
+	for i := range page.Items {
+		item, err := r.Decode(&page.Items[i])
+		if err != nil {
+			return nil, err
+		}
+		decoded.Items[i] = *item
+	}
+	return &decoded, nil
+}
+
+// DecodeRawPage returns page as a PaginatedResponse whose items' payloads are decoded like DecodeRaw does.
+// The first item which cannot be decoded stops the decoding and its error is returned.
+func (r *Registry) DecodeRawPage(page *RawPaginatedResponse) (*PaginatedResponse, error) {
+	decoded := &PaginatedResponse{
+		Items:          make([]Response, len(page.Items)),
+		Total:          page.Total,
+		Skip:           page.Skip,
+		Take:           page.Take,
+		RequestContext: page.RequestContext,
+		Stale:          page.Stale,
+	}
+	for i := range page.Items {
+		item, err := r.DecodeRaw(&page.Items[i])
+		if err != nil {
+			return nil, err
+		}
+		decoded.Items[i] = *item
+	}
+	return decoded, nil
+}
+
+// Content retrieves a content from ch and decodes its payload into the type registered for its definition.
+// The payload is decoded once when ch is an OnlineChannel or a PreviewChannel, which keep it undecoded.
+func (r *Registry) Content(ctx context.Context, ch Channel, config *ContentOptions) (*Response, error) {
+	if raw, ok := ch.(rawChannel); ok {
+		content, err := raw.ContentRaw(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		return r.DecodeRaw(content)
+	}
+	content, err := ch.Content(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	return r.Decode(content)
+}
+
+// Search runs a search on ch and decodes the payloads of the results into the types registered for
+// their definitions. The payloads are decoded once when ch is an OnlineChannel or a PreviewChannel.
+func (r *Registry) Search(ctx context.Context, ch Channel, config *SearchOptions) (*PaginatedResponse, error) {
+	if raw, ok := ch.(rawChannel); ok {
+		page, err := raw.SearchRaw(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		return r.DecodeRawPage(page)
+	}
+	page, err := ch.Search(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+	return r.DecodePage(page)
+}
+
+// WithRegistry returns a Middleware decoding the payloads of the contents returned by
+// the wrapped channel into the types registered in r.
+func WithRegistry(r *Registry) Middleware {
+	return func(next Channel) Channel {
+		return &registryChannel{Channel: next, registry: r}
+	}
+}
+
+type registryChannel struct {
+	Channel
+	registry *Registry
+}
+
+func (c *registryChannel) Content(ctx context.Context, config *ContentOptions) (*Response, error) {
+	return c.registry.Content(ctx, c.Channel, config)
+}
+
+func (c *registryChannel) Search(ctx context.Context, config *SearchOptions) (*PaginatedResponse, error) {
+	return c.registry.Search(ctx, c.Channel, config)
+}
@@ -0,0 +1,158 @@
+package contentchef
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+type testPage struct {
+	Title string `json:"title"`
+}
+
+func TestRegistry_Register(t *testing.T) {
+	r := NewRegistry()
+	tests := []struct {
+		name       string
+		definition string
+		v          interface{}
+		wantErr    bool
+	}{
+		{name: "value", definition: "article", v: testPayload{}},
+		{name: "pointer", definition: "page", v: &testPage{}},
+		{name: "already registered", definition: "article", v: testPage{}, wantErr: true},
+		{name: "empty definition", definition: "", v: testPage{}, wantErr: true},
+		{name: "nil type", definition: "video", v: nil, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := r.Register(tt.definition, tt.v); (err != nil) != tt.wantErr {
+				t.Errorf("Register() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+	if err := r.RegisterFallback(nil); err == nil {
+		t.Errorf("Expected a nil fallback to return an error")
+	}
+}
+
+func TestRegistry_Search(t *testing.T) {
+	r := NewRegistry()
+	r.Register("article", testPayload{})
+	r.Register("page", &testPage{})
+	ch := NewSnapshotChannel(&Snapshot{Contents: testContents()})
+
+	page, err := r.Search(ctx, ch, &SearchOptions{PublicID: []string{"go", "rust", "home"}})
+	if err != nil {
+		t.Fatalf("Search() returned error: %v", err)
+	}
+	want := []interface{}{
+		&testPayload{Title: "Learning Go", Views: 30, Topics: []string{"go", "tutorial"}, Author: map[string]interface{}{"name": "Ann"}},
+		&testPayload{Title: "Learning Rust", Views: 10, Topics: []string{"rust"}, Author: map[string]interface{}{"name": "Bob"}},
+		&testPage{Title: "Home"},
+	}
+	for i, item := range page.Items {
+		if !reflect.DeepEqual(item.Payload, want[i]) {
+			t.Errorf("Items[%d].Payload = %#v, want %#v", i, item.Payload, want[i])
+		}
+	}
+	if page.Total != 3 || page.Items[0].Definition != "article" {
+		t.Errorf("Expected the page and its items to be kept, got %+v", page)
+	}
+}
+
+func TestRegistry_unknownDefinition(t *testing.T) {
+	r := NewRegistry()
+	r.Register("article", testPayload{})
+	ch := NewSnapshotChannel(&Snapshot{Contents: testContents()})
+
+	_, err := r.Search(ctx, ch, &SearchOptions{})
+	if err, ok := err.(*UnknownDefinitionError); !ok || err.Definition != "page" || err.PublicID != "home" {
+		t.Errorf("Expected an UnknownDefinitionError for the page, got %v", err)
+	}
+
+	r.RegisterFallback(map[string]interface{}{})
+	got, err := r.Content(ctx, ch, &ContentOptions{PublicID: "home"})
+	if err != nil {
+		t.Fatalf("Content() returned error: %v", err)
+	}
+	if want := &map[string]interface{}{"title": "Home"}; !reflect.DeepEqual(got.Payload, want) {
+		t.Errorf("Content().Payload = %#v, want %#v", got.Payload, want)
+	}
+}
+
+func TestRegistry_decodeError(t *testing.T) {
+	r := NewRegistry()
+	r.Register("page", testPage{})
+	content := &Response{PublicID: "home", Definition: "page", Payload: map[string]interface{}{"title": 1}}
+	if _, err := r.Decode(content); err == nil {
+		t.Errorf("Expected a payload of the wrong type to return an error")
+	}
+}
+
+func TestWithRegistry(t *testing.T) {
+	r := NewRegistry()
+	r.Register("article", testPayload{})
+	r.Register("page", testPage{})
+	contents := testContents()
+	ch := Chain(NewSnapshotChannel(&Snapshot{Contents: contents}), WithRegistry(r))
+
+	home, err := ch.Content(ctx, &ContentOptions{PublicID: "home"})
+	if err != nil {
+		t.Fatalf("Content() returned error: %v", err)
+	}
+	if _, ok := home.Payload.(*testPage); !ok {
+		t.Errorf("Content().Payload = %#v, want a *testPage", home.Payload)
+	}
+	items, err := SearchAll(ctx, ch, &SearchOptions{Take: 2})
+	if err != nil {
+		t.Fatalf("SearchAll() returned error: %v", err)
+	}
+	for _, item := range items {
+		if _, ok := item.Payload.(*testPayload); !ok && item.Definition == "article" {
+			t.Errorf("Payload of %s = %#v, want a *testPayload", item.PublicID, item.Payload)
+		}
+	}
+	if _, ok := contents[0].Payload.(map[string]interface{}); !ok {
+		t.Errorf("Expected the channel's contents not to be modified")
+	}
+}
+
+type testCounter struct {
+	Count int64 `json:"count"`
+}
+
+func TestRegistry_decodesRawPayloads(t *testing.T) {
+	setup()
+	defer teardown()
+
+	// 2^53 + 1 cannot be decoded into a float64, it is kept only when the payload is decoded once
+	const count = int64(1<<53 + 1)
+	body := fmt.Sprintf(`{"publicId": "a", "definition": "counter", "payload": {"count": %d}}`, count)
+	mux.HandleFunc("/space/my_space/online/content/aChannel", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, body)
+	})
+	mux.HandleFunc("/space/my_space/online/search/v2/aChannel", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"items": [%s], "total": 1}`, body)
+	})
+
+	r := NewRegistry()
+	r.Register("counter", testCounter{})
+	ch, _ := client.GetOnlineChannel("aChannel", "superSecret")
+
+	content, err := r.Content(ctx, ch, &ContentOptions{PublicID: "a"})
+	if err != nil {
+		t.Fatalf("Content() returned error: %v", err)
+	}
+	if got := content.Payload.(*testCounter).Count; got != count || content.PublicID != "a" {
+		t.Errorf("Content() = %+v with count %d, want %d", content, got, count)
+	}
+	page, err := r.Search(ctx, ch, &SearchOptions{})
+	if err != nil {
+		t.Fatalf("Search() returned error: %v", err)
+	}
+	if got := page.Items[0].Payload.(*testCounter).Count; got != count || page.Total != 1 {
+		t.Errorf("Search() = %+v with count %d, want %d", page, got, count)
+	}
+}
@@ -0,0 +1,238 @@
+package contentchef
+
+import (
+	"context"
+	"strings"
+)
+
+const defaultResolveDepth = 3
+
+// ResolverOptions configures how a Resolver finds references in payloads.
+//
+// A reference is either a publicId string or an object with a publicId string field.
+// References are looked up at Paths, and anywhere in the payload if IsReference is set.
+type ResolverOptions struct {
+	// Dot separated paths of the payload fields holding references, like "author" or "blocks.image".
+	// Arrays met along a path are walked element by element, and a field can hold an array of references
+	Paths []string
+	// IsReference, if set, is called on every value of the payload and returns the publicId
+	// of the value if it is a reference
+	IsReference func(v interface{}) (string, bool)
+	// How many levels of references are resolved. Defaults to 3
+	MaxDepth int
+}
+
+// Resolver inlines the contents referenced by payloads, fetching them through a channel.
+//
+// Every reference found is replaced with the payload of the content it refers to, whose own
+// references are resolved in turn, up to MaxDepth levels. The references of a level are fetched
+// in batches, every content is fetched once per call, and references to a content being resolved
+// are left as they are, so that cycles do not repeat. References to missing contents are left as they are.
+type Resolver struct {
+	channel     Channel
+	paths       [][]string
+	isReference func(v interface{}) (string, bool)
+	maxDepth    int
+}
+
+// NewResolver returns a new Resolver reference fetching contents through ch.
+//
+// It takes the channel and a reference to a ResolverOptions struct.
+func NewResolver(ch Channel, o *ResolverOptions) *Resolver {
+	if o == nil {
+		o = &ResolverOptions{}
+	}
+	r := &Resolver{
+		channel:     ch,
+		isReference: o.IsReference,
+		maxDepth:    o.MaxDepth,
+	}
+	if r.maxDepth <= 0 {
+		r.maxDepth = defaultResolveDepth
+	}
+	for _, path := range o.Paths {
+		r.paths = append(r.paths, strings.Split(path, "."))
+	}
+	return r
+}
+
+// reference is a reference found in a payload.
+type reference struct {
+	publicID string
+	// set replaces the reference in its payload
+	set func(v interface{})
+	// the publicIds of the contents the reference is nested in
+	ancestors []string
+}
+
+func (ref *reference) isCycle() bool {
+	for _, id := range ref.ancestors {
+		if id == ref.publicID {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve resolves the references of content, replacing its payload with the resolved one.
+func (r *Resolver) Resolve(ctx context.Context, content *Response) error {
+	contents := []Response{*content}
+	err := r.ResolveAll(ctx, contents)
+	*content = contents[0]
+	return err
+}
+
+// ResolveAll resolves the references of every content, replacing their payloads with the resolved ones.
+// The references of all the contents are fetched together.
+func (r *Resolver) ResolveAll(ctx context.Context, contents []Response) error {
+	var level []*reference
+	for i := range contents {
+		payload := copyValue(normalizePayload(contents[i].Payload))
+		contents[i].Payload = payload
+		level = r.collect(payload, []string{contents[i].PublicID}, level)
+	}
+
+	fetched := make(map[string]*Response)
+	for depth := 1; depth <= r.maxDepth && len(level) > 0; depth++ {
+		var ids []string
+		for _, ref := range level {
+			if _, ok := fetched[ref.publicID]; !ok && !ref.isCycle() {
+				ids = append(ids, ref.publicID)
+			}
+		}
+		if len(ids) > 0 {
+			result, err := contentMany(ctx, r.channel, ids)
+			if err != nil {
+				return err
+			}
+			for _, id := range uniqueIDs(ids) {
+				if content, ok := result.Contents[id]; ok {
+					fetched[id] = &content
+				} else {
+					fetched[id] = nil
+				}
+			}
+		}
+
+		var next []*reference
+		for _, ref := range level {
+			target := fetched[ref.publicID]
+			if target == nil || ref.isCycle() {
+				continue
+			}
+			// every reference gets its own copy, so that the copies can be resolved independently
+			payload := copyValue(normalizePayload(target.Payload))
+			ref.set(payload)
+			ancestors := append(append([]string(nil), ref.ancestors...), ref.publicID)
+			next = r.collect(payload, ancestors, next)
+		}
+		level = next
+	}
+	return nil
+}
+
+// collect appends to refs the references found in payload.
+func (r *Resolver) collect(payload interface{}, ancestors []string, refs []*reference) []*reference {
+	found := func(id string, set func(interface{})) {
+		refs = append(refs, &reference{publicID: id, set: set, ancestors: ancestors})
+	}
+	for _, path := range r.paths {
+		walkPath(payload, path, found)
+	}
+	if r.isReference != nil {
+		r.walkChildren(payload, found)
+	}
+	return refs
+}
+
+// walkPath calls found for the references held by the field at path.
+func walkPath(v interface{}, path []string, found func(string, func(interface{}))) {
+	switch value := v.(type) {
+	case []interface{}:
+		for _, item := range value {
+			walkPath(item, path, found)
+		}
+		return
+	case map[string]interface{}:
+		if len(path) == 0 {
+			return
+		}
+		name := path[0]
+		field, ok := value[name]
+		if !ok {
+			return
+		}
+		if len(path) > 1 {
+			walkPath(field, path[1:], found)
+			return
+		}
+		if items, ok := field.([]interface{}); ok {
+			for i, item := range items {
+				i := i
+				if id, ok := referenceID(item); ok {
+					found(id, func(v interface{}) { items[i] = v })
+				}
+			}
+			return
+		}
+		if id, ok := referenceID(field); ok {
+			found(id, func(v interface{}) { value[name] = v })
+		}
+	}
+}
+
+// referenceID returns the publicId of a publicId string or of an object with a publicId field.
+func referenceID(v interface{}) (string, bool) {
+	switch value := v.(type) {
+	case string:
+		return value, value != ""
+	case map[string]interface{}:
+		id, ok := value["publicId"].(string)
+		return id, ok && id != ""
+	}
+	return "", false
+}
+
+// walkAll calls found for every value of v detected by IsReference, without walking into references.
+func (r *Resolver) walkAll(v interface{}, set func(interface{}), found func(string, func(interface{}))) {
+	if id, ok := r.isReference(v); ok {
+		found(id, set)
+		return
+	}
+	r.walkChildren(v, found)
+}
+
+// walkChildren calls walkAll on the fields of an object or the items of an array.
+func (r *Resolver) walkChildren(v interface{}, found func(string, func(interface{}))) {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		for name, field := range value {
+			name := name
+			r.walkAll(field, func(v interface{}) { value[name] = v }, found)
+		}
+	case []interface{}:
+		for i, item := range value {
+			i := i
+			r.walkAll(item, func(v interface{}) { value[i] = v }, found)
+		}
+	}
+}
+
+// copyValue returns a deep copy of a decoded JSON value.
+func copyValue(v interface{}) interface{} {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		c := make(map[string]interface{}, len(value))
+		for k, v := range value {
+			c[k] = copyValue(v)
+		}
+		return c
+	case []interface{}:
+		c := make([]interface{}, len(value))
+		for i, v := range value {
+			c[i] = copyValue(v)
+		}
+		return c
+	}
+	return v
+}
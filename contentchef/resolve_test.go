@@ -0,0 +1,112 @@
+package contentchef
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func resolverContents() []Response {
+	payloads := map[string]string{
+		"post":   `{"title": "Post", "author": "ann", "related": ["other", {"publicId": "missing"}], "blocks": [{"image": "logo"}]}`,
+		"other":  `{"title": "Other", "author": {"publicId": "bob"}, "related": ["post"]}`,
+		"ann":    `{"name": "Ann", "avatar": {"$ref": "logo"}}`,
+		"bob":    `{"name": "Bob", "related": ["ann"]}`,
+		"logo":   `{"url": "logo.png"}`,
+		"nested": `{"related": ["post"]}`,
+	}
+	var contents []Response
+	for _, id := range []string{"post", "other", "ann", "bob", "logo", "nested"} {
+		var payload interface{}
+		json.Unmarshal([]byte(payloads[id]), &payload)
+		contents = append(contents, Response{PublicID: id, Payload: payload})
+	}
+	return contents
+}
+
+func decodeJSON(t *testing.T, s string) interface{} {
+	var v interface{}
+	if err := json.Unmarshal([]byte(s), &v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestResolver_Resolve(t *testing.T) {
+	contents := resolverContents()
+	ch := &countingChannel{Channel: NewSnapshotChannel(&Snapshot{Contents: contents})}
+	r := NewResolver(ch, &ResolverOptions{
+		Paths: []string{"author", "related", "blocks.image"},
+		IsReference: func(v interface{}) (string, bool) {
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				return "", false
+			}
+			id, ok := m["$ref"].(string)
+			return id, ok
+		},
+	})
+
+	post := contents[0]
+	if err := r.Resolve(ctx, &post); err != nil {
+		t.Fatalf("Resolve() returned error: %v", err)
+	}
+	want := decodeJSON(t, `{
+		"title": "Post",
+		"author": {"name": "Ann", "avatar": {"url": "logo.png"}},
+		"related": [
+			{"title": "Other", "author": {"name": "Bob", "related": [{"name": "Ann", "avatar": {"$ref": "logo"}}]}, "related": ["post"]},
+			{"publicId": "missing"}
+		],
+		"blocks": [{"image": {"url": "logo.png"}}]
+	}`)
+	if !reflect.DeepEqual(post.Payload, want) {
+		got, _ := json.Marshal(post.Payload)
+		t.Errorf("Resolve() payload = %s", got)
+	}
+
+	// one search per level, every content fetched once: the third level only has ann, already fetched,
+	// whose avatar is past MaxDepth
+	wantSearches := [][]string{{"ann", "other", "missing", "logo"}, {"bob"}}
+	if !reflect.DeepEqual(ch.searches, wantSearches) {
+		t.Errorf("Searches = %v, want %v", ch.searches, wantSearches)
+	}
+
+	// the snapshot contents are left untouched
+	if contents[0].Payload.(map[string]interface{})["author"] != "ann" {
+		t.Errorf("Expected the fetched payloads not to be modified")
+	}
+}
+
+func TestResolver_ResolveAll(t *testing.T) {
+	contents := resolverContents()
+	r := NewResolver(NewSnapshotChannel(&Snapshot{Contents: resolverContents()}), &ResolverOptions{Paths: []string{"related"}, MaxDepth: 1})
+
+	items := contents[1:2]
+	items = append(items, contents[5])
+	if err := r.ResolveAll(ctx, items); err != nil {
+		t.Fatalf("ResolveAll() returned error: %v", err)
+	}
+
+	// references to a content being resolved are cycles and left as they are,
+	// and only MaxDepth levels are resolved
+	wantOther := decodeJSON(t, `{"title": "Other", "author": {"publicId": "bob"}, "related": [
+		{"title": "Post", "author": "ann", "related": ["other", {"publicId": "missing"}], "blocks": [{"image": "logo"}]}
+	]}`)
+	if !reflect.DeepEqual(items[0].Payload, wantOther) {
+		got, _ := json.Marshal(items[0].Payload)
+		t.Errorf("ResolveAll() payload = %s", got)
+	}
+	related := items[1].Payload.(map[string]interface{})["related"].([]interface{})
+	if title := related[0].(map[string]interface{})["title"]; title != "Post" {
+		t.Errorf("Expected the related post to be inlined, got %v", related[0])
+	}
+}
+
+func TestResolver_error(t *testing.T) {
+	r := NewResolver(NewSnapshotChannel(&Snapshot{}), &ResolverOptions{Paths: []string{"author"}})
+	content := Response{PublicID: "a", Payload: map[string]interface{}{"author": "b"}}
+	if err := r.Resolve(nil, &content); err == nil {
+		t.Errorf("Expected a nil context to return an error")
+	}
+}
@@ -0,0 +1,143 @@
+package contentchef
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	defaultRetryMaxAttempts = 3
+	defaultRetryBaseDelay   = 100 * time.Millisecond
+	defaultRetryMaxDelay    = 10 * time.Second
+)
+
+// RetryOptions configures how the Client retries failed requests.
+//
+// Requests are retried on transient network errors and on 429, 502, 503 and 504 responses.
+// Zero values are replaced by sensible defaults.
+type RetryOptions struct {
+	// The maximum number of attempts, including the first one. Defaults to 3
+	MaxAttempts int
+	// The delay before the first retry, doubled on every following retry. Defaults to 100ms
+	BaseDelay time.Duration
+	// The maximum delay between two attempts. Defaults to 10s.
+	// If the server asks with a Retry-After header to wait longer than MaxDelay the request is not retried
+	MaxDelay time.Duration
+	// The fraction of the delay, between 0 and 1, which is randomized to spread retries of concurrent callers
+	Jitter float64
+}
+
+func (o *RetryOptions) maxAttempts() int {
+	if o.MaxAttempts == 0 {
+		return defaultRetryMaxAttempts
+	}
+	return o.MaxAttempts
+}
+
+func (o *RetryOptions) maxDelay() time.Duration {
+	if o.MaxDelay == 0 {
+		return defaultRetryMaxDelay
+	}
+	return o.MaxDelay
+}
+
+// backoff returns the delay to wait after the given failed attempt.
+func (o *RetryOptions) backoff(attempt int) time.Duration {
+	delay := o.BaseDelay
+	if delay == 0 {
+		delay = defaultRetryBaseDelay
+	}
+	max := o.maxDelay()
+	for i := 1; i < attempt && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	if o.Jitter > 0 {
+		delay -= time.Duration(o.Jitter * rand.Float64() * float64(delay))
+	}
+	return delay
+}
+
+// next reports whether the given attempt, which ended with res or err, must be retried
+// and how long to wait before doing it.
+func (o *RetryOptions) next(attempt int, res *http.Response, err error) (time.Duration, bool) {
+	if o == nil || attempt >= o.maxAttempts() {
+		return 0, false
+	}
+	if err != nil {
+		return o.backoff(attempt), isTransient(err)
+	}
+	if !isRetryableStatus(res.StatusCode) {
+		return 0, false
+	}
+	if after, ok := parseRetryAfter(res.Header.Get("Retry-After"), time.Now()); ok {
+		return after, after <= o.maxDelay()
+	}
+	return o.backoff(attempt), true
+}
+
+func isRetryableStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// isTransient reports whether err is a network error which is worth retrying.
+func isTransient(err error) bool {
+	if e, ok := err.(*url.Error); ok {
+		err = e.Err
+	}
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		return true
+	}
+	if e, ok := err.(net.Error); ok && e.Timeout() {
+		return true
+	}
+	_, ok := err.(*net.OpError)
+	return ok
+}
+
+// parseRetryAfter parses the value of a Retry-After header, which can be
+// either a number of seconds or an HTTP date.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	after := date.Sub(now)
+	if after < 0 {
+		after = 0
+	}
+	return after, true
+}
+
+// sleep waits for the given duration or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
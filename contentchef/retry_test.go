@@ -0,0 +1,189 @@
+package contentchef
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestDo_retriesRetryableStatus(t *testing.T) {
+	setup()
+	defer teardown()
+	client.retry = &RetryOptions{MaxAttempts: 3, BaseDelay: time.Millisecond}
+
+	calls := 0
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		if calls < 3 {
+			http.Error(w, "Service Unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"A":"a"}`)
+	})
+
+	req, _ := client.newRequest(http.MethodGet, "/", nil)
+	body := new(struct{ A string })
+	_, err := client.do(context.Background(), req, body)
+	if err != nil {
+		t.Fatalf("do(): %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("Expected 3 requests, got %d", calls)
+	}
+	if body.A != "a" {
+		t.Errorf("Response body = %v, expected a", body.A)
+	}
+}
+
+func TestDo_doesNotRetryClientErrors(t *testing.T) {
+	setup()
+	defer teardown()
+	client.retry = &RetryOptions{MaxAttempts: 3, BaseDelay: time.Millisecond}
+
+	calls := 0
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		http.Error(w, "Not Found", http.StatusNotFound)
+	})
+
+	req, _ := client.newRequest(http.MethodGet, "/", nil)
+	_, err := client.do(context.Background(), req, nil)
+	if err == nil {
+		t.Error("Expected HTTP 404 error.")
+	}
+	if calls != 1 {
+		t.Errorf("Expected 1 request, got %d", calls)
+	}
+}
+
+func TestDo_retryStopsAtMaxAttempts(t *testing.T) {
+	setup()
+	defer teardown()
+	client.retry = &RetryOptions{MaxAttempts: 2, BaseDelay: time.Millisecond}
+
+	calls := 0
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.Header().Set("Retry-After", "0")
+		http.Error(w, "Too Many Requests", http.StatusTooManyRequests)
+	})
+
+	req, _ := client.newRequest(http.MethodGet, "/", nil)
+	_, err := client.do(context.Background(), req, nil)
+	if err == nil {
+		t.Error("Expected HTTP 429 error.")
+	}
+	if calls != 2 {
+		t.Errorf("Expected 2 requests, got %d", calls)
+	}
+}
+
+func TestDo_retryHonorsContext(t *testing.T) {
+	setup()
+	defer teardown()
+	client.retry = &RetryOptions{MaxAttempts: 5, BaseDelay: time.Hour}
+
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Bad Gateway", http.StatusBadGateway)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	req, _ := client.newRequest(http.MethodGet, "/", nil)
+	_, err := client.do(ctx, req, nil)
+	if err != context.DeadlineExceeded {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+func TestRetryOptions_backoff(t *testing.T) {
+	o := &RetryOptions{BaseDelay: time.Second, MaxDelay: 5 * time.Second}
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{attempt: 1, want: time.Second},
+		{attempt: 2, want: 2 * time.Second},
+		{attempt: 3, want: 4 * time.Second},
+		{attempt: 4, want: 5 * time.Second},
+		{attempt: 40, want: 5 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := o.backoff(tt.attempt); got != tt.want {
+			t.Errorf("backoff(%d) = %v, want %v", tt.attempt, got, tt.want)
+		}
+	}
+
+	o.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if got := o.backoff(1); got < 500*time.Millisecond || got > time.Second {
+			t.Fatalf("backoff(1) with jitter = %v, want between 500ms and 1s", got)
+		}
+	}
+}
+
+func TestRetryOptions_next(t *testing.T) {
+	o := &RetryOptions{MaxAttempts: 3, BaseDelay: time.Second, MaxDelay: time.Minute}
+	status := func(code int, retryAfter string) *http.Response {
+		res := &http.Response{StatusCode: code, Header: http.Header{}}
+		if retryAfter != "" {
+			res.Header.Set("Retry-After", retryAfter)
+		}
+		return res
+	}
+	tests := []struct {
+		name      string
+		o         *RetryOptions
+		attempt   int
+		res       *http.Response
+		err       error
+		wantDelay time.Duration
+		wantRetry bool
+	}{
+		{name: "nil options never retry", o: nil, attempt: 1, res: status(503, ""), wantRetry: false},
+		{name: "503 is retried", o: o, attempt: 1, res: status(503, ""), wantDelay: time.Second, wantRetry: true},
+		{name: "500 is not retried", o: o, attempt: 1, res: status(500, ""), wantRetry: false},
+		{name: "last attempt is not retried", o: o, attempt: 3, res: status(503, ""), wantRetry: false},
+		{name: "Retry-After is honored", o: o, attempt: 1, res: status(429, "7"), wantDelay: 7 * time.Second, wantRetry: true},
+		{name: "Retry-After longer than MaxDelay is not retried", o: o, attempt: 1, res: status(429, "120"), wantDelay: 2 * time.Minute, wantRetry: false},
+		{name: "network errors are retried", o: o, attempt: 2, err: &url.Error{Op: "Get", Err: &net.OpError{Op: "dial", Err: errors.New("refused")}}, wantDelay: 2 * time.Second, wantRetry: true},
+		{name: "unexpected EOF is retried", o: o, attempt: 1, err: &url.Error{Op: "Get", Err: io.ErrUnexpectedEOF}, wantDelay: time.Second, wantRetry: true},
+		{name: "other errors are not retried", o: o, attempt: 1, err: &url.Error{Op: "Get", Err: errors.New("stopped after 10 redirects")}, wantDelay: time.Second, wantRetry: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delay, retry := tt.o.next(tt.attempt, tt.res, tt.err)
+			if retry != tt.wantRetry || (retry && delay != tt.wantDelay) {
+				t.Errorf("next() = %v, %v, want %v, %v", delay, retry, tt.wantDelay, tt.wantRetry)
+			}
+		})
+	}
+}
+
+func Test_parseRetryAfter(t *testing.T) {
+	now, _ := time.Parse(time.RFC3339, "2020-04-09T22:00:00Z")
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOk bool
+	}{
+		{value: "", wantOk: false},
+		{value: "3", want: 3 * time.Second, wantOk: true},
+		{value: "-3", wantOk: false},
+		{value: "Thu, 09 Apr 2020 22:00:30 GMT", want: 30 * time.Second, wantOk: true},
+		{value: "Thu, 09 Apr 2020 21:00:00 GMT", want: 0, wantOk: true},
+		{value: "soon", wantOk: false},
+	}
+	for _, tt := range tests {
+		got, ok := parseRetryAfter(tt.value, now)
+		if ok != tt.wantOk || got != tt.want {
+			t.Errorf("parseRetryAfter(%q) = %v, %v, want %v, %v", tt.value, got, ok, tt.want, tt.wantOk)
+		}
+	}
+}
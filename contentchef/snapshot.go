@@ -0,0 +1,222 @@
+package contentchef
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SnapshotVersion is the version of the snapshot format written by Snapshot.Save.
+const SnapshotVersion = 1
+
+const (
+	snapshotManifestFile = "manifest.json"
+	snapshotContentsFile = "contents.json"
+)
+
+// SnapshotManifest describes the contents of a Snapshot.
+type SnapshotManifest struct {
+	// The version of the snapshot format
+	Version int `json:"version"`
+	// When the snapshot was taken
+	CreatedAt time.Time `json:"createdAt"`
+	// The publishing channel the contents were taken from
+	Channel string `json:"channel"`
+	// The number of contents in the snapshot
+	Total int `json:"total"`
+	// The request context of the last page searched
+	RequestContext RequestContext `json:"requestContext"`
+	// The hex encoded SHA-256 of the contents file, checked when the snapshot is loaded
+	SHA256 string `json:"sha256"`
+}
+
+// Snapshot is a copy of the contents exposed by a channel, which can be saved to
+// and loaded from a directory and served by a SnapshotChannel without network access.
+type Snapshot struct {
+	Manifest SnapshotManifest
+	Contents []Response
+}
+
+// pageRecorder is a Searcher keeping the request context of the last page returned.
+type pageRecorder struct {
+	Searcher
+	requestContext RequestContext
+}
+
+func (r *pageRecorder) Search(ctx context.Context, config *SearchOptions) (*PaginatedResponse, error) {
+	page, err := r.Searcher.Search(ctx, config)
+	if err == nil {
+		r.requestContext = page.RequestContext
+	}
+	return page, err
+}
+
+// TakeSnapshot returns a Snapshot of every content matching a search, walking through all the result pages.
+//
+// It takes the same parameters of NewSearchIterator, a nil config takes every content of the channel.
+func TakeSnapshot(ctx context.Context, s Searcher, config *SearchOptions) (*Snapshot, error) {
+	recorder := &pageRecorder{Searcher: s}
+	contents, err := SearchAll(ctx, recorder, config)
+	if err != nil {
+		return nil, err
+	}
+	if contents == nil {
+		contents = []Response{}
+	}
+	return &Snapshot{
+		Manifest: SnapshotManifest{
+			Version:        SnapshotVersion,
+			CreatedAt:      time.Now().UTC(),
+			Channel:        recorder.requestContext.PublishingChannel,
+			Total:          len(contents),
+			RequestContext: recorder.requestContext,
+		},
+		Contents: contents,
+	}, nil
+}
+
+// Save writes the snapshot to dir, which is created if needed, as a manifest.json and a contents.json file.
+//
+// The manifest is written last, so a directory holding a manifest always holds a complete snapshot.
+func (s *Snapshot) Save(dir string) error {
+	contents, err := json.Marshal(s.Contents)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(contents)
+	s.Manifest.Version = SnapshotVersion
+	s.Manifest.Total = len(s.Contents)
+	s.Manifest.SHA256 = hex.EncodeToString(sum[:])
+	manifest, err := json.MarshalIndent(s.Manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = os.MkdirAll(dir, 0755)
+	if err != nil {
+		return err
+	}
+	err = writeFileAtomic(filepath.Join(dir, snapshotContentsFile), contents)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(filepath.Join(dir, snapshotManifestFile), manifest)
+}
+
+// writeFileAtomic writes data to a temporary file renamed to name, so that name is never left half written.
+func writeFileAtomic(name string, data []byte) error {
+	f, err := ioutil.TempFile(filepath.Dir(name), filepath.Base(name)+".tmp")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(f.Name(), 0644)
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), name)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
+
+// LoadSnapshot reads a snapshot saved in dir.
+//
+// It returns an error if the snapshot was written with an unsupported version of the format
+// or if its contents do not match the checksum of the manifest.
+func LoadSnapshot(dir string) (*Snapshot, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, snapshotManifestFile))
+	if err != nil {
+		return nil, err
+	}
+	s := &Snapshot{}
+	err = json.Unmarshal(data, &s.Manifest)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshot manifest: %v", err)
+	}
+	if s.Manifest.Version != SnapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d", s.Manifest.Version)
+	}
+
+	data, err = ioutil.ReadFile(filepath.Join(dir, snapshotContentsFile))
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	if hex.EncodeToString(sum[:]) != s.Manifest.SHA256 {
+		return nil, errors.New("snapshot contents do not match the manifest checksum")
+	}
+	err = json.Unmarshal(data, &s.Contents)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshot contents: %v", err)
+	}
+	return s, nil
+}
+
+// SnapshotChannel retrieves contents out of a Snapshot, without calling the API.
+//
+// Contents are searched with LocalSearch, contents missing from the snapshot are
+// reported with a 404 APIError, so IsNotFound works as with the other channels.
+// The payloads of the returned contents are shared with the snapshot and must not be modified.
+type SnapshotChannel struct {
+	snapshot *Snapshot
+	index    map[string]int
+}
+
+// NewSnapshotChannel returns a SnapshotChannel reference serving the contents of s.
+func NewSnapshotChannel(s *Snapshot) *SnapshotChannel {
+	index := make(map[string]int, len(s.Contents))
+	for i, content := range s.Contents {
+		index[content.PublicID] = i
+	}
+	return &SnapshotChannel{snapshot: s, index: index}
+}
+
+// Content returns the content of the snapshot with the publicID of config.
+func (s *SnapshotChannel) Content(ctx context.Context, config *ContentOptions) (*Response, error) {
+	if ctx == nil {
+		return nil, errors.New("context must be non-nil")
+	}
+	if config == nil {
+		config = &ContentOptions{}
+	}
+	i, ok := s.index[config.PublicID]
+	if !ok {
+		return nil, &APIError{
+			StatusCode: http.StatusNotFound,
+			Method:     http.MethodGet,
+			Message:    fmt.Sprintf("content %q not found in snapshot", config.PublicID),
+		}
+	}
+	r := s.snapshot.Contents[i]
+	return &r, nil
+}
+
+// Search returns the contents of the snapshot matching config, evaluated like the API does.
+func (s *SnapshotChannel) Search(ctx context.Context, config *SearchOptions) (*PaginatedResponse, error) {
+	if ctx == nil {
+		return nil, errors.New("context must be non-nil")
+	}
+	if config != nil {
+		err := config.PropFilters.Validate()
+		if err != nil {
+			return nil, err
+		}
+	}
+	page := LocalSearch(s.snapshot.Contents, config)
+	page.RequestContext = s.snapshot.Manifest.RequestContext
+	return page, nil
+}
@@ -0,0 +1,175 @@
+package contentchef
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func tempDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "contentchef")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+func TestTakeSnapshot(t *testing.T) {
+	setup()
+	defer teardown()
+
+	mux.HandleFunc("/space/my_space/online/search/v2/aChannel", func(w http.ResponseWriter, r *http.Request) {
+		items := `{"publicId": "a", "payload": {"title": "A"}, "metadata": {"tags": ["x"]}}`
+		if r.URL.Query().Get("skip") == "1" {
+			items = `{"publicId": "b", "payload": {"title": "B"}}`
+		}
+		fmt.Fprintf(w, `{"items": [%s], "total": 2, "requestContext": {"publishingChannel": "aChannel", "cloudName": "aCloud"}}`, items)
+	})
+
+	ch, _ := client.GetOnlineChannel("aChannel", "superSecret")
+	s, err := TakeSnapshot(ctx, ch, &SearchOptions{Take: 1})
+	if err != nil {
+		t.Fatalf("TakeSnapshot() returned error: %v", err)
+	}
+	if got := publicIDs(s.Contents); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("TakeSnapshot() contents = %v, want [a b]", got)
+	}
+	m := s.Manifest
+	if m.Version != SnapshotVersion || m.Channel != "aChannel" || m.Total != 2 || m.RequestContext.CloudName != "aCloud" || m.CreatedAt.IsZero() {
+		t.Errorf("TakeSnapshot() manifest = %+v", m)
+	}
+}
+
+func TestSnapshot_SaveAndLoad(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+	dir = filepath.Join(dir, "snapshot")
+
+	s, err := TakeSnapshot(ctx, &fakeSearcher{contents: testContents()}, nil)
+	if err != nil {
+		t.Fatalf("TakeSnapshot() returned error: %v", err)
+	}
+	err = s.Save(dir)
+	if err != nil {
+		t.Fatalf("Save() returned error: %v", err)
+	}
+
+	got, err := LoadSnapshot(dir)
+	if err != nil {
+		t.Fatalf("LoadSnapshot() returned error: %v", err)
+	}
+	if !reflect.DeepEqual(got.Manifest, s.Manifest) {
+		t.Errorf("LoadSnapshot() manifest = %+v, want %+v", got.Manifest, s.Manifest)
+	}
+	if !reflect.DeepEqual(publicIDs(got.Contents), publicIDs(s.Contents)) {
+		t.Errorf("LoadSnapshot() contents = %v, want %v", publicIDs(got.Contents), publicIDs(s.Contents))
+	}
+	if title := got.Contents[1].Payload.(map[string]interface{})["title"]; title != "Learning Rust" {
+		t.Errorf("Expected payloads to be saved, got title %v", title)
+	}
+}
+
+func TestLoadSnapshot_errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		corrupt func(dir string) error
+		wantErr string
+	}{
+		{
+			name: "unsupported version",
+			corrupt: func(dir string) error {
+				return ioutil.WriteFile(filepath.Join(dir, "manifest.json"), []byte(`{"version": 99}`), 0644)
+			},
+			wantErr: "unsupported snapshot version 99",
+		},
+		{
+			name: "contents changed",
+			corrupt: func(dir string) error {
+				return ioutil.WriteFile(filepath.Join(dir, "contents.json"), []byte(`[]`), 0644)
+			},
+			wantErr: "checksum",
+		},
+		{
+			name: "missing manifest",
+			corrupt: func(dir string) error {
+				return os.Remove(filepath.Join(dir, "manifest.json"))
+			},
+			wantErr: "manifest.json",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir, cleanup := tempDir(t)
+			defer cleanup()
+			s := &Snapshot{Contents: contentsWithIDs("a")}
+			if err := s.Save(dir); err != nil {
+				t.Fatalf("Save() returned error: %v", err)
+			}
+			if err := tt.corrupt(dir); err != nil {
+				t.Fatal(err)
+			}
+			_, err := LoadSnapshot(dir)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("LoadSnapshot() error = %v, want it to contain %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSnapshotChannel(t *testing.T) {
+	s := &Snapshot{
+		Manifest: SnapshotManifest{RequestContext: RequestContext{PublishingChannel: "aChannel"}},
+		Contents: testContents(),
+	}
+	ch := NewSnapshotChannel(s)
+
+	got, err := ch.Content(ctx, &ContentOptions{PublicID: "rust"})
+	if err != nil {
+		t.Fatalf("Content() returned error: %v", err)
+	}
+	if got.PublicID != "rust" {
+		t.Errorf("Content().PublicID = %v, want rust", got.PublicID)
+	}
+	_, err = ch.Content(ctx, &ContentOptions{PublicID: "missing"})
+	if !IsNotFound(err) {
+		t.Errorf("Expected a not found error, got %v", err)
+	}
+	_, err = ch.Content(ctx, nil)
+	if !IsNotFound(err) {
+		t.Errorf("Expected a nil config to return a not found error, got %v", err)
+	}
+
+	filters, _ := Where("topics").Equals("go").Build()
+	page, err := ch.Search(ctx, &SearchOptions{
+		Take:        1,
+		PropFilters: filters,
+		Sorting:     Sorting{{FieldName: "views", Ascending: true}},
+	})
+	if err != nil {
+		t.Fatalf("Search() returned error: %v", err)
+	}
+	if got := publicIDs(page.Items); page.Total != 2 || !reflect.DeepEqual(got, []string{"gopher"}) {
+		t.Errorf("Search() = %v of %d, want [gopher] of 2", got, page.Total)
+	}
+	if page.RequestContext.PublishingChannel != "aChannel" {
+		t.Errorf("Search().RequestContext = %+v", page.RequestContext)
+	}
+
+	page, err = ch.Search(ctx, &SearchOptions{Skip: -1, Take: 2})
+	if err != nil || len(page.Items) != 2 || page.Skip != 0 {
+		t.Errorf("Search() with a negative skip = %+v, %v", page, err)
+	}
+
+	_, err = ch.Search(ctx, &SearchOptions{PropFilters: PropFilters{Condition: "XOR"}})
+	if err == nil {
+		t.Errorf("Expected invalid filters to return an error")
+	}
+	if _, err := ch.Search(nil, nil); err == nil {
+		t.Errorf("Expected a nil context to return an error")
+	}
+}
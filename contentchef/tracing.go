@@ -0,0 +1,113 @@
+package contentchef
+
+import (
+	"context"
+	"net/http"
+	"sync"
+
+	"github.com/google/go-querystring/query"
+)
+
+// Tracer starts a span for every channel call.
+//
+// It follows the shape of the OpenTelemetry tracer, so that it can be implemented by a thin
+// adapter without the SDK depending on any tracing library.
+type Tracer interface {
+	// Start starts a span, returning a context carrying it.
+	Start(ctx context.Context, spanName string) (context.Context, Span)
+	// Inject writes the span context carried by ctx to the headers of an outgoing request.
+	Inject(ctx context.Context, header http.Header)
+}
+
+// Span is an operation traced by a Tracer.
+type Span interface {
+	SetAttributes(attributes ...Attribute)
+	RecordError(err error)
+	End()
+}
+
+// Attribute is a key value pair describing a Span.
+type Attribute struct {
+	Key   string
+	Value interface{}
+}
+
+// The attributes set on the spans of channel calls.
+const (
+	AttributeSpaceID    = "contentchef.space_id"
+	AttributeChannel    = "contentchef.channel"
+	AttributePreview    = "contentchef.preview"
+	AttributeState      = "contentchef.state"
+	AttributePublicID   = "contentchef.public_id"
+	AttributeSearch     = "contentchef.search"
+	AttributeRetries    = "contentchef.retries"
+	AttributeStale      = "contentchef.stale"
+	AttributeStatusCode = "http.status_code"
+)
+
+// callTrace collects what happens to the requests of a traced call.
+type callTrace struct {
+	mu         sync.Mutex
+	attempts   int
+	statusCode int
+}
+
+type callTraceKey struct{}
+
+// recordAttempt records an attempt of the traced call carried by ctx, if any.
+func recordAttempt(ctx context.Context, attempt, statusCode int) {
+	t, ok := ctx.Value(callTraceKey{}).(*callTrace)
+	if !ok {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.attempts = attempt
+	t.statusCode = statusCode
+}
+
+// startSpan starts the span of a channel call described by the RequestInfo carried by ctx.
+// The returned function ends it, recording the outcome of the call.
+func (c *Client) startSpan(ctx context.Context, attributes ...Attribute) (context.Context, func(stale bool, err error)) {
+	if c.tracer == nil || ctx == nil {
+		return ctx, func(bool, error) {}
+	}
+	info, _ := RequestInfoFromContext(ctx)
+	ctx, span := c.tracer.Start(ctx, "contentchef."+info.Operation)
+	span.SetAttributes(append([]Attribute{
+		{AttributeSpaceID, c.SpaceID},
+		{AttributeChannel, info.Channel},
+		{AttributePreview, info.Preview},
+	}, attributes...)...)
+
+	t := &callTrace{}
+	ctx = context.WithValue(ctx, callTraceKey{}, t)
+	return ctx, func(stale bool, err error) {
+		t.mu.Lock()
+		attempts, statusCode := t.attempts, t.statusCode
+		t.mu.Unlock()
+
+		if attempts > 0 {
+			span.SetAttributes(
+				Attribute{AttributeRetries, attempts - 1},
+				Attribute{AttributeStatusCode, statusCode},
+			)
+		}
+		if stale {
+			span.SetAttributes(Attribute{AttributeStale, true})
+		}
+		if err != nil {
+			span.RecordError(err)
+		}
+		span.End()
+	}
+}
+
+// searchSummary describes a search in the query string form sent to the API.
+func searchSummary(config *SearchOptions) string {
+	qs, err := query.Values(config)
+	if err != nil {
+		return ""
+	}
+	return qs.Encode()
+}
@@ -0,0 +1,162 @@
+package contentchef
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeSpan struct {
+	name       string
+	attributes map[string]interface{}
+	errors     []error
+	ended      bool
+}
+
+func (s *fakeSpan) SetAttributes(attributes ...Attribute) {
+	for _, a := range attributes {
+		s.attributes[a.Key] = a.Value
+	}
+}
+
+func (s *fakeSpan) RecordError(err error) { s.errors = append(s.errors, err) }
+func (s *fakeSpan) End()                  { s.ended = true }
+
+type spanKey struct{}
+
+// fakeTracer records its spans and injects their name in the X-Span header.
+type fakeTracer struct {
+	mu    sync.Mutex
+	spans []*fakeSpan
+}
+
+func (t *fakeTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	span := &fakeSpan{name: name, attributes: map[string]interface{}{}}
+	t.spans = append(t.spans, span)
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+func (t *fakeTracer) Inject(ctx context.Context, header http.Header) {
+	if span, ok := ctx.Value(spanKey{}).(*fakeSpan); ok {
+		header.Set("X-Span", span.name)
+	}
+}
+
+func TestClient_tracer(t *testing.T) {
+	setup()
+	defer teardown()
+	tracer := &fakeTracer{}
+	client.tracer = tracer
+	client.retry = &RetryOptions{MaxAttempts: 3, BaseDelay: time.Millisecond}
+
+	calls := 0
+	mux.HandleFunc("/space/my_space/preview/live/content/aChannel", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Span") != "contentchef.content" {
+			t.Errorf("Expected the span to be injected, got headers %v", r.Header)
+		}
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, `{"publicId": "foo"}`)
+	})
+	mux.HandleFunc("/space/my_space/online/search/v2/aChannel", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"message": "bad request"}`, http.StatusBadRequest)
+	})
+
+	preview, _ := client.GetPreviewChannel("aChannel", "superSecret", "live")
+	if _, err := preview.Content(ctx, &ContentOptions{PublicID: "foo"}); err != nil {
+		t.Fatalf("Content() returned error: %v", err)
+	}
+	online, _ := client.GetOnlineChannel("aChannel", "superSecret")
+	_, searchErr := online.Search(ctx, &SearchOptions{Take: 5, ContentDefinition: []string{"article"}})
+	if searchErr == nil {
+		t.Fatal("Expected Search() to return an error")
+	}
+
+	if len(tracer.spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(tracer.spans))
+	}
+	content, search := tracer.spans[0], tracer.spans[1]
+	wantContent := map[string]interface{}{
+		AttributeSpaceID:    "my_space",
+		AttributeChannel:    "aChannel",
+		AttributePreview:    true,
+		AttributeState:      "live",
+		AttributePublicID:   "foo",
+		AttributeRetries:    1,
+		AttributeStatusCode: http.StatusOK,
+	}
+	if content.name != "contentchef.content" || !content.ended || !reflect.DeepEqual(content.attributes, wantContent) {
+		t.Errorf("Content span = %+v, want attributes %v", content, wantContent)
+	}
+	wantSearch := map[string]interface{}{
+		AttributeSpaceID:    "my_space",
+		AttributeChannel:    "aChannel",
+		AttributePreview:    false,
+		AttributeSearch:     "contentDefinition=article&skip=0&take=5",
+		AttributeRetries:    0,
+		AttributeStatusCode: http.StatusBadRequest,
+	}
+	if search.name != "contentchef.search" || !search.ended || !reflect.DeepEqual(search.attributes, wantSearch) {
+		t.Errorf("Search span = %+v, want attributes %v", search, wantSearch)
+	}
+	if !reflect.DeepEqual(search.errors, []error{searchErr}) {
+		t.Errorf("Search span errors = %v, want %v", search.errors, searchErr)
+	}
+}
+
+func TestClient_tracerCacheHit(t *testing.T) {
+	setup()
+	defer teardown()
+	tracer := &fakeTracer{}
+	client.tracer = tracer
+	client.cache = NewLRUCache(10, time.Minute)
+
+	mux.HandleFunc("/space/my_space/online/content/aChannel", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"publicId": "foo"}`)
+	})
+
+	ch, _ := client.GetOnlineChannel("aChannel", "superSecret")
+	for i := 0; i < 2; i++ {
+		if _, err := ch.Content(ctx, &ContentOptions{PublicID: "foo"}); err != nil {
+			t.Fatalf("Content() returned error: %v", err)
+		}
+	}
+	if len(tracer.spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(tracer.spans))
+	}
+	if _, ok := tracer.spans[1].attributes[AttributeStatusCode]; ok {
+		t.Errorf("Expected no status code for a cached response, got %v", tracer.spans[1].attributes)
+	}
+}
+
+func TestOnlineChannel_nilConfig(t *testing.T) {
+	for _, tracer := range []Tracer{nil, &fakeTracer{}} {
+		setup()
+		client.tracer = tracer
+
+		mux.HandleFunc("/space/my_space/online/content/aChannel", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"publicId": "foo"}`)
+		})
+		mux.HandleFunc("/space/my_space/online/search/v2/aChannel", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, `{"items": [], "total": 0}`)
+		})
+
+		ch, _ := client.GetOnlineChannel("aChannel", "superSecret")
+		if _, err := ch.Content(ctx, nil); err != nil {
+			t.Errorf("Content() with a nil config returned error: %v", err)
+		}
+		if _, err := ch.Search(ctx, nil); err != nil {
+			t.Errorf("Search() with a nil config returned error: %v", err)
+		}
+		teardown()
+	}
+}
@@ -0,0 +1,176 @@
+package contentchef
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// WebhookSecretHeader is the header carrying the shared secret of a webhook call.
+	WebhookSecretHeader = "X-Chef-Webhook-Secret"
+	// WebhookSignatureHeader is the header carrying the signature of a webhook call,
+	// in the form sha256=<hex encoded HMAC-SHA256 of the body>.
+	WebhookSignatureHeader = "X-Chef-Signature"
+
+	maxWebhookBodySize = 1 << 20
+)
+
+// WebhookEventType is the kind of change notified by a webhook call.
+type WebhookEventType string
+
+// The webhook event types.
+const (
+	EventPublish   WebhookEventType = "publish"
+	EventUnpublish WebhookEventType = "unpublish"
+)
+
+// WebhookEvent is a change notified by ContentChef.
+type WebhookEvent struct {
+	Type       WebhookEventType `json:"type"`
+	SpaceID    string           `json:"spaceId"`
+	PublicID   string           `json:"publicId"`
+	Definition string           `json:"definition"`
+	Repository string           `json:"repository"`
+	Channel    string           `json:"channel"`
+	Timestamp  time.Time        `json:"timestamp"`
+}
+
+// WebhookHandlerFunc is called for every event received by a WebhookHandler.
+type WebhookHandlerFunc func(ctx context.Context, e WebhookEvent)
+
+// WebhookHandler is an http.Handler receiving ContentChef publish and unpublish webhook calls.
+//
+// Every call is verified with either the shared secret or the signature, decoded, and dispatched
+// to the callbacks registered for its type. Calls which cannot be verified are answered with 401,
+// calls which cannot be decoded with 400.
+type WebhookHandler struct {
+	secret string
+
+	mu        sync.RWMutex
+	callbacks map[WebhookEventType][]WebhookHandlerFunc
+	all       []WebhookHandlerFunc
+}
+
+// NewWebhookHandler returns a new WebhookHandler reference.
+//
+// It takes the secret configured on the ContentChef webhook, which must not be an empty string.
+// Calls are accepted if they carry the secret in the X-Chef-Webhook-Secret header or if they are
+// signed with it in the X-Chef-Signature header.
+func NewWebhookHandler(secret string) (*WebhookHandler, error) {
+	if secret == "" {
+		return nil, errors.New("secret seems to be an empty string")
+	}
+	return &WebhookHandler{
+		secret:    secret,
+		callbacks: make(map[WebhookEventType][]WebhookHandlerFunc),
+	}, nil
+}
+
+// On registers a callback for the events of the given type.
+func (h *WebhookHandler) On(t WebhookEventType, fn WebhookHandlerFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.callbacks[t] = append(h.callbacks[t], fn)
+}
+
+// OnAny registers a callback for every event.
+func (h *WebhookHandler) OnAny(fn WebhookHandlerFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.all = append(h.all, fn)
+}
+
+// InvalidateCache registers a callback purging the cache of c on every event of its space,
+// so that changed contents are fetched again instead of waiting for their TTL.
+// Events of other spaces are ignored, events without a space are applied.
+//
+// Unpublish events also purge the stale responses kept for StaleIfError,
+// so that unpublished contents are not served when a later request fails.
+func (h *WebhookHandler) InvalidateCache(c *Client) {
+	h.OnAny(func(ctx context.Context, e WebhookEvent) {
+		if e.SpaceID != "" && e.SpaceID != c.SpaceID {
+			return
+		}
+		if c.cache != nil {
+			c.cache.Purge()
+		}
+		if c.stale != nil && e.Type == EventUnpublish {
+			c.stale.Purge()
+		}
+	})
+}
+
+// ServeHTTP verifies, decodes and dispatches a webhook call.
+func (h *WebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookBodySize))
+	if err != nil {
+		http.Error(w, "cannot read body", http.StatusBadRequest)
+		return
+	}
+	if !h.verify(r.Header, body) {
+		http.Error(w, "invalid secret or signature", http.StatusUnauthorized)
+		return
+	}
+
+	var e WebhookEvent
+	err = json.Unmarshal(body, &e)
+	if err != nil {
+		http.Error(w, "cannot decode event", http.StatusBadRequest)
+		return
+	}
+	if e.Type != EventPublish && e.Type != EventUnpublish {
+		http.Error(w, "unknown event type", http.StatusBadRequest)
+		return
+	}
+
+	h.dispatch(r.Context(), e)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *WebhookHandler) verify(header http.Header, body []byte) bool {
+	if secret := header.Get(WebhookSecretHeader); secret != "" {
+		return subtle.ConstantTimeCompare([]byte(secret), []byte(h.secret)) == 1
+	}
+	signature := header.Get(WebhookSignatureHeader)
+	if !strings.HasPrefix(signature, "sha256=") {
+		return false
+	}
+	got, err := hex.DecodeString(strings.TrimPrefix(signature, "sha256="))
+	if err != nil {
+		return false
+	}
+	return hmac.Equal(got, SignWebhook(h.secret, body))
+}
+
+func (h *WebhookHandler) dispatch(ctx context.Context, e WebhookEvent) {
+	h.mu.RLock()
+	callbacks := append(append([]WebhookHandlerFunc{}, h.all...), h.callbacks[e.Type]...)
+	h.mu.RUnlock()
+
+	for _, fn := range callbacks {
+		fn(ctx, e)
+	}
+}
+
+// SignWebhook returns the HMAC-SHA256 of body computed with secret,
+// which is sent hex encoded in the X-Chef-Signature header.
+func SignWebhook(secret string, body []byte) []byte {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return mac.Sum(nil)
+}
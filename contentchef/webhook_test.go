@@ -0,0 +1,143 @@
+package contentchef
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testWebhookBody = `{"type": "publish", "spaceId": "my_space", "publicId": "home", "definition": "page", "repository": "site", "channel": "website", "timestamp": "2020-04-09T22:00:00Z"}`
+
+func TestNewWebhookHandler(t *testing.T) {
+	if _, err := NewWebhookHandler(""); err == nil {
+		t.Errorf("Expected an error with an empty secret")
+	}
+}
+
+func TestWebhookHandler_ServeHTTP(t *testing.T) {
+	signature := "sha256=" + hex.EncodeToString(SignWebhook("s3cret", []byte(testWebhookBody)))
+	tests := []struct {
+		name       string
+		method     string
+		header     map[string]string
+		body       string
+		wantStatus int
+		wantEvents int
+	}{
+		{name: "valid secret", method: http.MethodPost, header: map[string]string{WebhookSecretHeader: "s3cret"}, body: testWebhookBody, wantStatus: http.StatusNoContent, wantEvents: 1},
+		{name: "valid signature", method: http.MethodPost, header: map[string]string{WebhookSignatureHeader: signature}, body: testWebhookBody, wantStatus: http.StatusNoContent, wantEvents: 1},
+		{name: "wrong secret", method: http.MethodPost, header: map[string]string{WebhookSecretHeader: "wrong"}, body: testWebhookBody, wantStatus: http.StatusUnauthorized},
+		{name: "signature of another body", method: http.MethodPost, header: map[string]string{WebhookSignatureHeader: signature}, body: testWebhookBody + " ", wantStatus: http.StatusUnauthorized},
+		{name: "malformed signature", method: http.MethodPost, header: map[string]string{WebhookSignatureHeader: "sha256=zz"}, body: testWebhookBody, wantStatus: http.StatusUnauthorized},
+		{name: "no credentials", method: http.MethodPost, body: testWebhookBody, wantStatus: http.StatusUnauthorized},
+		{name: "invalid JSON", method: http.MethodPost, header: map[string]string{WebhookSecretHeader: "s3cret"}, body: `{`, wantStatus: http.StatusBadRequest},
+		{name: "unknown type", method: http.MethodPost, header: map[string]string{WebhookSecretHeader: "s3cret"}, body: `{"type": "delete"}`, wantStatus: http.StatusBadRequest},
+		{name: "wrong method", method: http.MethodGet, header: map[string]string{WebhookSecretHeader: "s3cret"}, wantStatus: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, _ := NewWebhookHandler("s3cret")
+			var events []WebhookEvent
+			h.On(EventPublish, func(ctx context.Context, e WebhookEvent) {
+				events = append(events, e)
+			})
+
+			req := httptest.NewRequest(tt.method, "/webhook", bytes.NewBufferString(tt.body))
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("ServeHTTP() status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if len(events) != tt.wantEvents {
+				t.Errorf("ServeHTTP() dispatched %d events, want %d", len(events), tt.wantEvents)
+			}
+		})
+	}
+}
+
+func TestWebhookHandler_dispatch(t *testing.T) {
+	h, _ := NewWebhookHandler("s3cret")
+	var got []string
+	h.On(EventPublish, func(ctx context.Context, e WebhookEvent) { got = append(got, "publish "+e.PublicID) })
+	h.On(EventUnpublish, func(ctx context.Context, e WebhookEvent) { got = append(got, "unpublish "+e.PublicID) })
+	h.OnAny(func(ctx context.Context, e WebhookEvent) { got = append(got, "any "+e.PublicID) })
+
+	for _, body := range []string{testWebhookBody, `{"type": "unpublish", "publicId": "about"}`} {
+		req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewBufferString(body))
+		req.Header.Set(WebhookSecretHeader, "s3cret")
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	want := []string{"any home", "publish home", "any about", "unpublish about"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Dispatched %v, want %v", got, want)
+	}
+}
+
+func TestWebhookHandler_decodesEvent(t *testing.T) {
+	h, _ := NewWebhookHandler("s3cret")
+	var got WebhookEvent
+	h.OnAny(func(ctx context.Context, e WebhookEvent) { got = e })
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewBufferString(testWebhookBody))
+	req.Header.Set(WebhookSecretHeader, "s3cret")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+
+	timestamp, _ := time.Parse(time.RFC3339, "2020-04-09T22:00:00Z")
+	want := WebhookEvent{
+		Type:       EventPublish,
+		SpaceID:    "my_space",
+		PublicID:   "home",
+		Definition: "page",
+		Repository: "site",
+		Channel:    "website",
+		Timestamp:  timestamp,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Decoded event = %+v, want %+v", got, want)
+	}
+}
+
+func TestWebhookHandler_InvalidateCache(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		wantCache int
+		wantStale int
+	}{
+		{name: "publish", body: testWebhookBody, wantCache: 0, wantStale: 1},
+		{name: "unpublish", body: strings.Replace(testWebhookBody, "publish", "unpublish", 1), wantCache: 0, wantStale: 0},
+		{name: "other space", body: strings.Replace(testWebhookBody, "my_space", "other_space", 1), wantCache: 1, wantStale: 1},
+		{name: "no space", body: `{"type": "unpublish", "publicId": "home"}`, wantCache: 0, wantStale: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setup()
+			defer teardown()
+			cache, stale := NewLRUCache(10, time.Hour), NewLRUCache(10, time.Hour)
+			client.cache, client.stale = cache, stale
+			cache.Set("key", []byte("{}"))
+			stale.Set("key", []byte("{}"))
+
+			h, _ := NewWebhookHandler("s3cret")
+			h.InvalidateCache(client)
+			req := httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewBufferString(tt.body))
+			req.Header.Set(WebhookSecretHeader, "s3cret")
+			h.ServeHTTP(httptest.NewRecorder(), req)
+
+			if cache.Len() != tt.wantCache || stale.Len() != tt.wantStale {
+				t.Errorf("Got %d cached and %d stale entries, want %d and %d", cache.Len(), stale.Len(), tt.wantCache, tt.wantStale)
+			}
+		})
+	}
+}